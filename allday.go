@@ -0,0 +1,59 @@
+package scheduleMerge
+
+import (
+	"time"
+)
+
+// Date is a calendar date without a time of day or a time zone.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// DateOf returns the date of t in the location of t.
+func DateOf(t time.Time) Date {
+	year, month, day := t.Date()
+	return Date{Year: year, Month: month, Day: day}
+}
+
+// In returns the instant the date starts at in loc. This is midnight, unless midnight was skipped by a daylight saving
+// time transition, in which case it is the first instant of the day (see WallClock.In).
+func (d Date) In(loc *time.Location) time.Time {
+	return WallClock{Year: d.Year, Month: d.Month, Day: d.Day}.In(loc)
+}
+
+// AddDays returns the date the given number of days after d.
+func (d Date) AddDays(days int) Date {
+	return DateOf(time.Date(d.Year, d.Month, d.Day+days, 0, 0, 0, 0, time.UTC))
+}
+
+// AllDayEvent is an optional interface for Event(s) that span whole days (e.g. holidays or leave requests) rather
+// than instants.
+//
+// Dates have no time zone. Before the Engine compares an AllDayEvent with any other event, it resolves its dates in
+// the Location of the Engine and stores the resulting instants via SetStartTime and SetEndTime. Depending on the
+// Location a day might be shorter or longer than 24 hours. Parts of a trimmed AllDayEvent are created by Clone and
+// bounded via SetStartTime and SetEndTime like any other Event, so they do not have to span whole days.
+type AllDayEvent interface {
+	Event
+	// GetStartDate returns the first day of the Event.
+	GetStartDate() Date
+	// GetEndDate returns the day after the last day of the Event. The dates of an Event are bounded as follows:
+	//
+	//	[StartDate, EndDate)
+	GetEndDate() Date
+}
+
+// resolveAllDay stores the instants the dates of the event start at in loc, if the event is an AllDayEvent.
+func resolveAllDay(event Event, loc *time.Location) {
+	allDayEvent, ok := event.(AllDayEvent)
+	if !ok {
+		return
+	}
+
+	if loc == nil {
+		loc = time.UTC
+	}
+	setBounds(event, allDayEvent.GetStartDate().In(loc), allDayEvent.GetEndDate().In(loc))
+}
@@ -0,0 +1,192 @@
+package scheduleMerge
+
+import (
+	"testing"
+	"time"
+)
+
+type allDayEvent struct {
+	event
+	StartDate Date
+	EndDate   Date
+}
+
+func (e *allDayEvent) GetStartDate() Date {
+	return e.StartDate
+}
+
+func (e *allDayEvent) GetEndDate() Date {
+	return e.EndDate
+}
+
+func (e *allDayEvent) Clone() Event {
+	clone := *e
+	return &clone
+}
+
+func TestDate_AddDays(t *testing.T) {
+	got := Date{Year: 2020, Month: time.December, Day: 31}.AddDays(1)
+	expected := Date{Year: 2021, Month: time.January, Day: 1}
+	if got != expected {
+		t.Fatalf("expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestEngine_Merge_AllDay(t *testing.T) {
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+
+	tcs := []struct {
+		name         string
+		testSchedule func() orderedSchedule
+		location     *time.Location
+		trimOverlaps bool
+		expected     [][2]time.Time
+	}{
+		{
+			// more desirable event (meeting)     :     [--)
+			// less desirable event (vacation day): [----------)
+			name: "vacation day around a meeting-trim",
+			testSchedule: func() orderedSchedule {
+				return orderedSchedule{
+					&allDayEvent{
+						event:     event{ID: 1},
+						StartDate: Date{Year: 2021, Month: time.June, Day: 1},
+						EndDate:   Date{Year: 2021, Month: time.June, Day: 2},
+					},
+					&event{
+						StartTime: time.Date(2021, 6, 1, 10, 0, 0, 0, berlin),
+						EndTime:   time.Date(2021, 6, 1, 11, 0, 0, 0, berlin),
+						ID:        2,
+					},
+				}
+			},
+			location:     berlin,
+			trimOverlaps: true,
+			expected: [][2]time.Time{
+				{time.Date(2021, 6, 1, 0, 0, 0, 0, berlin), time.Date(2021, 6, 1, 10, 0, 0, 0, berlin)},
+				{time.Date(2021, 6, 1, 10, 0, 0, 0, berlin), time.Date(2021, 6, 1, 11, 0, 0, 0, berlin)},
+				{time.Date(2021, 6, 1, 11, 0, 0, 0, berlin), time.Date(2021, 6, 2, 0, 0, 0, 0, berlin)},
+			},
+		},
+		{
+			// more desirable event (meeting)     :     [--)
+			// less desirable event (vacation day): [----------)
+			name: "vacation day around a meeting-no trim",
+			testSchedule: func() orderedSchedule {
+				return orderedSchedule{
+					&allDayEvent{
+						event:     event{ID: 1},
+						StartDate: Date{Year: 2021, Month: time.June, Day: 1},
+						EndDate:   Date{Year: 2021, Month: time.June, Day: 2},
+					},
+					&event{
+						StartTime: time.Date(2021, 6, 1, 10, 0, 0, 0, berlin),
+						EndTime:   time.Date(2021, 6, 1, 11, 0, 0, 0, berlin),
+						ID:        2,
+					},
+				}
+			},
+			location:     berlin,
+			trimOverlaps: false,
+			expected: [][2]time.Time{
+				{time.Date(2021, 6, 1, 10, 0, 0, 0, berlin), time.Date(2021, 6, 1, 11, 0, 0, 0, berlin)},
+			},
+		},
+		{
+			// Without a location the dates are resolved in UTC, where the meeting is on the next day.
+			// more desirable event (meeting)     :            [--)
+			// less desirable event (vacation day): [--------)
+			name: "vacation day before a meeting-default location",
+			testSchedule: func() orderedSchedule {
+				return orderedSchedule{
+					&allDayEvent{
+						event:     event{ID: 1},
+						StartDate: Date{Year: 2021, Month: time.June, Day: 1},
+						EndDate:   Date{Year: 2021, Month: time.June, Day: 2},
+					},
+					&event{
+						StartTime: time.Date(2021, 6, 2, 3, 0, 0, 0, berlin),
+						EndTime:   time.Date(2021, 6, 2, 4, 0, 0, 0, berlin),
+						ID:        2,
+					},
+				}
+			},
+			trimOverlaps: true,
+			expected: [][2]time.Time{
+				{time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 6, 2, 0, 0, 0, 0, time.UTC)},
+				{time.Date(2021, 6, 2, 3, 0, 0, 0, berlin), time.Date(2021, 6, 2, 4, 0, 0, 0, berlin)},
+			},
+		},
+		{
+			// On the spring-forward day in Europe/Berlin the vacation day lasts 23 hours.
+			// more desirable event (vacation day): [------)
+			// less desirable event (shift)       :     [------)
+			name: "spring forward vacation day over a shift-trim",
+			testSchedule: func() orderedSchedule {
+				return orderedSchedule{
+					&event{
+						StartTime: time.Date(2021, 3, 28, 20, 0, 0, 0, berlin),
+						EndTime:   time.Date(2021, 3, 29, 4, 0, 0, 0, berlin),
+						ID:        1,
+					},
+					&allDayEvent{
+						event:     event{ID: 2},
+						StartDate: Date{Year: 2021, Month: time.March, Day: 28},
+						EndDate:   Date{Year: 2021, Month: time.March, Day: 29},
+					},
+				}
+			},
+			location:     berlin,
+			trimOverlaps: true,
+			expected: [][2]time.Time{
+				{time.Date(2021, 3, 27, 23, 0, 0, 0, time.UTC), time.Date(2021, 3, 28, 22, 0, 0, 0, time.UTC)},
+				{time.Date(2021, 3, 29, 0, 0, 0, 0, berlin), time.Date(2021, 3, 29, 4, 0, 0, 0, berlin)},
+			},
+		},
+		{
+			// more desirable event (public holiday)    :     [----)
+			// less desirable event (three day vacation): [------------)
+			name: "vacation days around a public holiday-trim",
+			testSchedule: func() orderedSchedule {
+				return orderedSchedule{
+					&allDayEvent{
+						event:     event{ID: 1},
+						StartDate: Date{Year: 2021, Month: time.May, Day: 12},
+						EndDate:   Date{Year: 2021, Month: time.May, Day: 15},
+					},
+					&allDayEvent{
+						event:     event{ID: 2},
+						StartDate: Date{Year: 2021, Month: time.May, Day: 13},
+						EndDate:   Date{Year: 2021, Month: time.May, Day: 14},
+					},
+				}
+			},
+			location:     berlin,
+			trimOverlaps: true,
+			expected: [][2]time.Time{
+				{time.Date(2021, 5, 12, 0, 0, 0, 0, berlin), time.Date(2021, 5, 13, 0, 0, 0, 0, berlin)},
+				{time.Date(2021, 5, 13, 0, 0, 0, 0, berlin), time.Date(2021, 5, 14, 0, 0, 0, 0, berlin)},
+				{time.Date(2021, 5, 14, 0, 0, 0, 0, berlin), time.Date(2021, 5, 15, 0, 0, 0, 0, berlin)},
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			e := NewEngine(tc.testSchedule(), tc.trimOverlaps)
+			e.Location = tc.location
+			e.Merge()
+
+			if len(e.MergedSchedule) != len(tc.expected) {
+				t.Fatalf("expected %d events, got %d:\n%s", len(tc.expected), len(e.MergedSchedule), e.Timeline(TimelineOptions{}))
+			}
+			for i, got := range e.MergedSchedule {
+				expected := tc.expected[i]
+				if !got.GetStartTime().Equal(expected[0]) || !got.GetEndTime().Equal(expected[1]) {
+					t.Errorf("event %d: expected [%s, %s), got [%s, %s)", i+1, expected[0], expected[1],
+						got.GetStartTime(), got.GetEndTime())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package scheduleMerge
+
+import (
+	"testing"
+	"time"
+)
+
+// eventArena hands out preallocated events, as a caller might do with CloneEvent.
+type eventArena struct {
+	events []event
+}
+
+func (a *eventArena) clone(e Event) Event {
+	if len(a.events) == 0 {
+		return e.Clone()
+	}
+	part := &a.events[0]
+	a.events = a.events[1:]
+	*part = *(e.(*event))
+	return part
+}
+
+func TestEngine_Merge_Allocs(t *testing.T) {
+	const runs = 1000
+
+	tests := []struct {
+		name string
+		// event returns the i-th event to add to an engine that has already merged the events before it.
+		event func(i int) *event
+		// clones is the number of parts of trimmed events created by adding and merging an event. Apart from the copy
+		// of the merged schedule (see publish), they are the only allocations unless CloneEvent takes them from
+		// an arena.
+		clones float64
+	}{
+		{
+			name: "after all merged events",
+			event: func(i int) *event {
+				return &event{StartTime: at(2 * i), EndTime: at(2*i + 1), ID: i}
+			},
+		},
+		{
+			name: "before all merged events",
+			event: func(i int) *event {
+				return &event{StartTime: at(-2 * i), EndTime: at(-2*i + 1), ID: i}
+			},
+		},
+		{
+			name: "trimming the last merged event",
+			event: func(i int) *event {
+				return &event{StartTime: at(i), EndTime: at(i + 2), ID: i}
+			},
+			clones: 1,
+		},
+		{
+			name: "splitting a merged event",
+			event: func(i int) *event {
+				if i == 0 {
+					return &event{StartTime: at(0), EndTime: at(4 * (runs + 2)), ID: i}
+				}
+				return &event{StartTime: at(4 * i), EndTime: at(4*i + 1), ID: i}
+			},
+			// The part before and the part after the event.
+			clones: 2,
+		},
+	}
+	for _, tt := range tests {
+		for _, useArena := range []bool{true, false} {
+			name := tt.name + " with Clone"
+			if useArena {
+				name = tt.name + " with an arena"
+			}
+			t.Run(name, func(t *testing.T) {
+				events := make([]*event, runs+2)
+				for i := range events {
+					events[i] = tt.event(i)
+				}
+
+				e := NewEngine(schedule{events[0]}, true)
+				expected := tt.clones + 1
+				if useArena {
+					arena := &eventArena{events: make([]event, 2*len(events))}
+					e.CloneEvent = arena.clone
+					expected = 1
+				}
+				e.Merge()
+
+				next := 1
+				allocs := testing.AllocsPerRun(runs, func() {
+					e.Add(events[next])
+					e.Merge()
+					next++
+				})
+				if allocs > expected {
+					t.Fatalf("expected at most %v allocations per merged event, got %v", expected, allocs)
+				}
+				if err := CheckInvariants(e.MergedSchedule); err != nil {
+					t.Fatal(err)
+				}
+			})
+		}
+	}
+}
+
+func TestEngine_Merge_Allocs_Full(t *testing.T) {
+	const (
+		runs = 100
+		n    = 1000
+	)
+
+	// Every event trims the one before it.
+	s := make(schedule, n)
+	for i := range s {
+		s[i] = &event{StartTime: at(i), EndTime: at(i + 2), CreatedAt: at(i), ID: i}
+	}
+
+	for _, useArena := range []bool{true, false} {
+		name := "with Clone"
+		if useArena {
+			name = "with an arena"
+		}
+		t.Run(name, func(t *testing.T) {
+			e := NewEngine(s, true)
+			// The new merged schedule and conflicts (see Remerge), and the parts of the trimmed events.
+			expected := float64(2 + n - 1)
+			if useArena {
+				// The first Merge, the warm-up run of AllocsPerRun and the runs take their parts from the arena.
+				arena := &eventArena{events: make([]event, (runs+2)*n)}
+				e.CloneEvent = arena.clone
+				expected = 2
+			}
+			e.Merge()
+
+			allocs := testing.AllocsPerRun(runs, e.Remerge)
+			if allocs > expected {
+				t.Fatalf("expected at most %v allocations per merge, got %v", expected, allocs)
+			}
+			if len(e.Conflicts) != n-1 {
+				t.Fatalf("expected %d conflicts, got %d", n-1, len(e.Conflicts))
+			}
+			if err := CheckInvariants(e.MergedSchedule); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestEngine_CloneEvent(t *testing.T) {
+	origin := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var cloned []Event
+	e := NewEngine(schedule{
+		{StartTime: origin, EndTime: origin.Add(4 * time.Hour), CreatedAt: origin, ID: 1},
+		{StartTime: origin.Add(time.Hour), EndTime: origin.Add(2 * time.Hour), CreatedAt: origin.Add(time.Minute), ID: 2},
+	}, true)
+	e.CloneEvent = func(event Event) Event {
+		clone := event.Clone()
+		cloned = append(cloned, clone)
+		return clone
+	}
+	e.Merge()
+
+	if len(cloned) != 2 || cloned[0] != e.MergedSchedule[0] || cloned[1] != e.MergedSchedule[2] {
+		t.Fatalf("expected the parts of the trimmed event to be cloned by CloneEvent, got %v", mergedEvents(cloned))
+	}
+}
@@ -0,0 +1,160 @@
+package scheduleMerge
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// benchmarkSizes are the numbers of raw events the benchmarks merge.
+var benchmarkSizes = []int{10, 100, 1_000, 10_000, 100_000, 1_000_000}
+
+// benchmarkLarge enables the sizes above benchmarkMaxSize: merging a million raw events that are not sorted by time
+// takes hours, as every raw event might have to be merged in front of all the merged events.
+var benchmarkLarge = flag.Bool("large", false, "run the benchmarks with more than 10k raw events")
+
+// benchmarkMaxSize is the largest size the benchmarks merge without the -large flag.
+const benchmarkMaxSize = 10_000
+
+// benchmarkOrigin is the earliest start of the generated events.
+var benchmarkOrigin = time.Date(2020, 1, 6, 0, 0, 0, 0, time.UTC)
+
+// denseMeetings returns the calendar of a busy person: meetings of 15 minutes to 2 hours, aligned to quarter hours,
+// during the working hours of eight meetings a day on average, so most meetings collide with one or two others.
+func denseMeetings(seed int64, n int) schedule {
+	var (
+		r    = rand.New(rand.NewSource(seed))
+		days = n/8 + 1
+		s    = make(schedule, n)
+	)
+	for i := range s {
+		start := benchmarkOrigin.
+			AddDate(0, 0, r.Intn(days)).
+			Add(9*time.Hour + time.Duration(r.Intn(32))*15*time.Minute)
+		s[i] = &event{
+			StartTime: start,
+			EndTime:   start.Add(time.Duration(1+r.Intn(8)) * 15 * time.Minute),
+			CreatedAt: benchmarkOrigin.Add(time.Duration(r.Intn(n)) * time.Second),
+			ID:        i + 1,
+		}
+	}
+	return s
+}
+
+// sparseRoomBookings returns the bookings of a room: bookings of 1 to 4 hours spread over a long period, so only a few
+// of them collide.
+func sparseRoomBookings(seed int64, n int) schedule {
+	var (
+		r     = rand.New(rand.NewSource(seed))
+		hours = n * 24
+		s     = make(schedule, n)
+	)
+	for i := range s {
+		start := benchmarkOrigin.Add(time.Duration(r.Intn(hours)) * time.Hour)
+		s[i] = &event{
+			StartTime: start,
+			EndTime:   start.Add(time.Duration(1+r.Intn(4)) * time.Hour),
+			CreatedAt: benchmarkOrigin.Add(time.Duration(r.Intn(n)) * time.Second),
+			ID:        i + 1,
+		}
+	}
+	return s
+}
+
+// nestedOverlaps returns groups of up to 16 nested events, e.g. an on-call shift containing a working day containing a
+// meeting. Within a group, the shorter events are more desirable than the longer ones containing them.
+func nestedOverlaps(seed int64, n int) schedule {
+	var (
+		r = rand.New(rand.NewSource(seed))
+		s = make(schedule, n)
+	)
+	for i := range s {
+		group, depth := i/16, i%16
+		center := benchmarkOrigin.Add(time.Duration(group) * 48 * time.Hour)
+		margin := time.Duration(16-depth)*time.Hour + time.Duration(r.Intn(60))*time.Minute
+		s[i] = &event{
+			StartTime: center.Add(-margin),
+			EndTime:   center.Add(margin),
+			CreatedAt: benchmarkOrigin.Add(time.Duration(i) * time.Second),
+			ID:        i + 1,
+		}
+	}
+	return s
+}
+
+// allOverlapping returns events that all contain the same instant, the pathological case in which every event
+// collides with every other one.
+func allOverlapping(seed int64, n int) schedule {
+	var (
+		r      = rand.New(rand.NewSource(seed))
+		center = benchmarkOrigin.Add(time.Duration(n) * time.Minute)
+		s      = make(schedule, n)
+	)
+	for i := range s {
+		s[i] = &event{
+			StartTime: center.Add(-time.Duration(1+r.Intn(n)) * time.Minute),
+			EndTime:   center.Add(time.Duration(1+r.Intn(n)) * time.Minute),
+			CreatedAt: benchmarkOrigin.Add(time.Duration(r.Intn(n)) * time.Second),
+			ID:        i + 1,
+		}
+	}
+	return s
+}
+
+func BenchmarkEngine_Merge(b *testing.B) {
+	generators := []struct {
+		name     string
+		generate func(seed int64, n int) schedule
+	}{
+		{"dense meetings", denseMeetings},
+		{"sparse room bookings", sparseRoomBookings},
+		{"nested overlaps", nestedOverlaps},
+		{"all overlapping", allOverlapping},
+	}
+	for _, generator := range generators {
+		for _, trimOverlaps := range []bool{true, false} {
+			for _, n := range benchmarkSizes {
+				name := fmt.Sprintf("%s/trim=%v/n=%d", generator.name, trimOverlaps, n)
+				b.Run(name, func(b *testing.B) {
+					if n > benchmarkMaxSize && !*benchmarkLarge {
+						b.Skipf("%d raw events need the -large flag", n)
+					}
+					raw := generator.generate(1, n)
+					rawSchedule := make(schedule, n)
+					b.ReportAllocs()
+					b.ResetTimer()
+					for i := 0; i < b.N; i++ {
+						// NewEngine sorts the schedule in place, so every iteration starts from the generated order.
+						copy(rawSchedule, raw)
+						e := NewEngine(rawSchedule, trimOverlaps)
+						e.Merge()
+					}
+				})
+			}
+		}
+	}
+}
+
+func BenchmarkFindLastSafeMergedEventIndex(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			// A merged schedule of back-to-back events; the raw event starts after the last one.
+			merged := make([]Event, n)
+			for i := range merged {
+				start := benchmarkOrigin.Add(time.Duration(i) * time.Hour)
+				merged[i] = &event{StartTime: start, EndTime: start.Add(time.Hour), ID: i + 1}
+			}
+			last := benchmarkOrigin.Add(time.Duration(n) * time.Hour)
+			rawEvent := &event{StartTime: last, EndTime: last.Add(time.Hour), ID: n + 1}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if index := findLastSafeMergedEventIndex(rawEvent, merged); index != n-1 {
+					b.Fatalf("expected index %d, got %d", n-1, index)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package scheduleMerge
+
+import (
+	"time"
+)
+
+// Resolution describes what the Engine did with the less desirable side of a Conflict.
+type Resolution int
+
+const (
+	// Trimmed means the overlapping part of the less desirable event was cut away and the rest of it was kept.
+	Trimmed Resolution = iota + 1
+	// Discarded means the less desirable event was removed from the merged schedule altogether, either because it is
+	// not trimmed or because the overlap covered all of it.
+	Discarded
+)
+
+// String returns the name of the Resolution.
+func (r Resolution) String() string {
+	switch r {
+	case Trimmed:
+		return "trimmed"
+	case Discarded:
+		return "discarded"
+	default:
+		return "unknown"
+	}
+}
+
+// Conflict is an overlap between two events that was resolved by the Engine.
+type Conflict struct {
+	// Winner is the more desirable raw event that was inserted into the merged schedule.
+	Winner Event
+	// Loser is the less desirable raw event that lost the overlap. If an earlier conflict already trimmed the less
+	// desirable event, Loser is still the raw event and not the trimmed part of it.
+	Loser Event
+	// Start is the start time of the overlap. It is the zero Time if the overlap has no start (see UnboundedEvent).
+	Start time.Time
+	// End is the end time of the overlap. It is the zero Time if the overlap has no end (see UnboundedEvent).
+	End time.Time
+	// Resolution is what happened to Loser, according to its Policy and the TrimOverlaps setting of the engine. A
+	// trimmed Loser that was overlapped completely by several Winner(s) has no part left in the merged schedule,
+	// although each of its conflicts is Trimmed.
+	Resolution Resolution
+}
+
+// Duration returns the length of the overlap. An overlap without a start or without an end has the maximum
+// Duration.
+func (c Conflict) Duration() time.Duration {
+	if c.Start.IsZero() || c.End.IsZero() {
+		return maxDuration
+	}
+	return c.End.Sub(c.Start)
+}
+
+// recordConflict stores the overlap [start, end) between the rawEvent and the less desirable merged event, and
+// notifies the Observers.
+func (e *Engine) recordConflict(rawEvent, mergedEvent Event, start, end time.Time) {
+	original := e.original(mergedEvent)
+	resolution := Discarded
+	if e.trims(mergedEvent) && !covers(start, end, original) {
+		resolution = Trimmed
+	}
+
+	e.Conflicts = append(e.Conflicts, Conflict{
+		Winner:     rawEvent,
+		Loser:      original,
+		Start:      exposedBound(start),
+		End:        exposedBound(end),
+		Resolution: resolution,
+	})
+	e.notifyConflicts(e.Conflicts[len(e.Conflicts)-1:])
+}
+
+// covers reports whether the overlap [start, end) covers the whole event.
+func covers(start, end time.Time, event Event) bool {
+	return !start.After(startOf(event)) && !end.Before(endOf(event))
+}
+
+// fragment clones the merged event so that it can be trimmed, remembering the raw event it originates from.
+func (e *Engine) fragment(mergedEvent Event) Event {
+	part := e.cloneEvent(mergedEvent)
+	e.setOrigin(part, e.original(mergedEvent))
+	return part
+}
+
+// cloneEvent clones the event with CloneEvent, if set.
+func (e *Engine) cloneEvent(event Event) Event {
+	if e.CloneEvent != nil {
+		return e.CloneEvent(event)
+	}
+	return event.Clone()
+}
+
+// setOrigin remembers that the part originates from the raw event.
+func (e *Engine) setOrigin(part, rawEvent Event) {
+	if e.origins == nil {
+		e.origins = eventMap[Event]{}
+	}
+	e.origins.set(part, rawEvent)
+}
+
+// original returns the raw event the event originates from. Raw events are their own originals.
+func (e *Engine) original(event Event) Event {
+	if origin, ok := e.origins.lookup(event); ok {
+		return origin
+	}
+	return event
+}
@@ -0,0 +1,158 @@
+package scheduleMerge
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEngine_Conflicts(t *testing.T) {
+	// less desirable event: [------------)
+	// more desirable event:     [----)
+	// most desirable event:  [----)
+	raw := schedule{
+		{
+			StartTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2020, 1, 1, 6, 0, 0, 0, time.UTC),
+			CreatedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			ID:        1,
+		},
+		{
+			StartTime: time.Date(2020, 1, 1, 2, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2020, 1, 1, 4, 0, 0, 0, time.UTC),
+			CreatedAt: time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC),
+			ID:        2,
+		},
+		{
+			StartTime: time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2020, 1, 1, 3, 0, 0, 0, time.UTC),
+			CreatedAt: time.Date(2020, 1, 1, 2, 0, 0, 0, time.UTC),
+			ID:        3,
+		},
+	}
+
+	type conflict struct {
+		winner, loser int
+		start, end    time.Time
+		resolution    Resolution
+	}
+
+	tcs := []struct {
+		name         string
+		trimOverlaps bool
+		expected     []conflict
+	}{
+		{
+			name:         "trim",
+			trimOverlaps: true,
+			expected: []conflict{
+				{2, 1, time.Date(2020, 1, 1, 2, 0, 0, 0, time.UTC), time.Date(2020, 1, 1, 4, 0, 0, 0, time.UTC), Trimmed},
+				// The first part of event 1 ([0:00, 2:00)) loses [1:00, 2:00) to event 3.
+				{3, 1, time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC), time.Date(2020, 1, 1, 2, 0, 0, 0, time.UTC), Trimmed},
+				{3, 2, time.Date(2020, 1, 1, 2, 0, 0, 0, time.UTC), time.Date(2020, 1, 1, 3, 0, 0, 0, time.UTC), Trimmed},
+			},
+		},
+		{
+			name:         "no trim",
+			trimOverlaps: false,
+			expected: []conflict{
+				{2, 1, time.Date(2020, 1, 1, 2, 0, 0, 0, time.UTC), time.Date(2020, 1, 1, 4, 0, 0, 0, time.UTC), Discarded},
+				{3, 2, time.Date(2020, 1, 1, 2, 0, 0, 0, time.UTC), time.Date(2020, 1, 1, 3, 0, 0, 0, time.UTC), Discarded},
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			testSchedule := make(schedule, len(raw))
+			for i := range raw {
+				testSchedule[i] = raw[i].Clone().(*event)
+			}
+
+			e := NewEngine(testSchedule, tc.trimOverlaps)
+			e.Merge()
+
+			if len(e.Conflicts) != len(tc.expected) {
+				t.Fatalf("expected %d conflicts, got %d: %+v", len(tc.expected), len(e.Conflicts), e.Conflicts)
+			}
+			for i, got := range e.Conflicts {
+				expected := tc.expected[i]
+				if got.Winner.(*event).ID != expected.winner ||
+					got.Loser.(*event).ID != expected.loser ||
+					!got.Start.Equal(expected.start) ||
+					!got.End.Equal(expected.end) ||
+					got.Resolution != expected.resolution {
+					t.Errorf("conflict %d: expected %+v, got winner %d, loser %d, [%s, %s) %s", i, expected,
+						got.Winner.(*event).ID, got.Loser.(*event).ID, got.Start, got.End, got.Resolution)
+				}
+			}
+		})
+	}
+}
+
+func TestEngine_Conflicts_FullOverlap(t *testing.T) {
+	tcs := []struct {
+		// name is the type of the overlap between the more desirable event and the less desirable one (see merge).
+		name               string
+		low, high          [2]int
+		expectedResolution Resolution
+		expectedMerged     int
+	}{
+		{name: "3.a", low: [2]int{9, 12}, high: [2]int{9, 12}, expectedResolution: Discarded, expectedMerged: 1},
+		{name: "3.b", low: [2]int{10, 11}, high: [2]int{9, 12}, expectedResolution: Discarded, expectedMerged: 1},
+		{name: "3.c", low: [2]int{9, 12}, high: [2]int{10, 11}, expectedResolution: Trimmed, expectedMerged: 3},
+		{name: "3.d", low: [2]int{9, 11}, high: [2]int{9, 12}, expectedResolution: Discarded, expectedMerged: 1},
+		{name: "3.e", low: [2]int{10, 12}, high: [2]int{9, 12}, expectedResolution: Discarded, expectedMerged: 1},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			low := &event{
+				StartTime: time.Date(2020, 1, 1, tc.low[0], 0, 0, 0, time.UTC),
+				EndTime:   time.Date(2020, 1, 1, tc.low[1], 0, 0, 0, time.UTC),
+				CreatedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+				ID:        1,
+			}
+			high := &event{
+				StartTime: time.Date(2020, 1, 1, tc.high[0], 0, 0, 0, time.UTC),
+				EndTime:   time.Date(2020, 1, 1, tc.high[1], 0, 0, 0, time.UTC),
+				CreatedAt: time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC),
+				ID:        2,
+			}
+			e := NewEngine(schedule{low, high}, true)
+			e.Merge()
+
+			if len(e.Conflicts) != 1 || e.Conflicts[0].Resolution != tc.expectedResolution {
+				t.Fatalf("expected a single %s conflict, got %+v", tc.expectedResolution, e.Conflicts)
+			}
+			if len(e.MergedSchedule) != tc.expectedMerged {
+				t.Fatalf("expected %d merged events, got %d", tc.expectedMerged, len(e.MergedSchedule))
+			}
+		})
+	}
+
+	// A part of a trimmed event that is covered completely is only trimmed, as the rest of the event is kept.
+	e := NewEngine(schedule{
+		{
+			StartTime: time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC),
+			CreatedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			ID:        1,
+		},
+		{
+			StartTime: time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2020, 1, 1, 11, 0, 0, 0, time.UTC),
+			CreatedAt: time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC),
+			ID:        2,
+		},
+		{
+			StartTime: time.Date(2020, 1, 1, 11, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC),
+			CreatedAt: time.Date(2020, 1, 1, 2, 0, 0, 0, time.UTC),
+			ID:        3,
+		},
+	}, true)
+	e.Merge()
+	if len(e.Conflicts) != 2 || e.Conflicts[1].Resolution != Trimmed {
+		t.Fatalf("expected the second conflict to be trimmed, got %+v", e.Conflicts)
+	}
+}
@@ -0,0 +1,177 @@
+package scheduleMerge
+
+import (
+	"time"
+)
+
+// maxConstraintCuts bounds the number of cuts the engine makes to satisfy its constraints after a single merge, in
+// case a Constraint keeps proposing cuts that do not resolve its violations.
+const maxConstraintCuts = 1 << 16
+
+// Constraint is a rule the merged schedule has to satisfy, e.g. a labour rule such as "at most 10 working hours per
+// day". Constraints are checked after merging; every violation is resolved by cutting time from the least desirable
+// event the violation proposes to cut (see Engine.Constraints).
+type Constraint interface {
+	// Check returns the violations of the rule in the merged schedule, which is sorted by time and free of overlaps.
+	// Every violation proposes the cuts that would resolve it, or at least bring it closer to being resolved.
+	Check(mergedSchedule []Event) []Violation
+}
+
+// Cut is a span of time to remove from an event of the merged schedule.
+type Cut struct {
+	// Event is the event of the merged schedule to cut.
+	Event Event
+	// Start is the start time of the span to remove.
+	Start time.Time
+	// End is the end time of the span to remove.
+	End time.Time
+}
+
+// Violation is a violation of a Constraint.
+type Violation struct {
+	// Start is the start time of the span the rule is violated in.
+	Start time.Time
+	// End is the end time of the span the rule is violated in.
+	End time.Time
+	// Message describes the violation.
+	Message string
+	// Cuts are the cuts proposed by the Constraint. The engine makes only the one of the least desirable event that is
+	// not pinned, and checks the constraints again afterwards.
+	Cuts []Cut
+
+	// Constraint is the violated Constraint. It is set by the engine.
+	Constraint Constraint
+	// Cut is the cut the engine made to resolve the violation, with the raw event it cut from. Its span is the time
+	// that was removed, or the bounds of the raw event if the raw event was discarded. It is set by the engine, and
+	// is nil if the violation could not be resolved because all the proposed events are pinned.
+	Cut *Cut
+	// Resolution is what happened to the raw event of Cut, according to its Policy and the TrimOverlaps setting of the
+	// engine. It is set by the engine and is zero if the violation could not be resolved.
+	Resolution Resolution
+}
+
+// enforceConstraints cuts the merged schedule until it satisfies the constraints of the engine, recording every
+// violation in Violations. Pinned events are never cut.
+func (e *Engine) enforceConstraints() {
+	if len(e.Constraints) == 0 {
+		return
+	}
+
+	rank := rankByEvent(e.RawSchedule)
+	// unresolved holds the violations that proposed no cut the engine can make, so that they are reported once.
+	type violationKey struct {
+		constraint int
+		start, end time.Time
+		message    string
+	}
+	unresolved := map[violationKey]bool{}
+
+	for cuts := 0; cuts < maxConstraintCuts; cuts++ {
+		merged := make(eventMap[bool], len(e.MergedSchedule))
+		for _, mergedEvent := range e.MergedSchedule {
+			merged.set(mergedEvent, true)
+		}
+
+		violation, found := Violation{}, false
+	search:
+		for i, constraint := range e.Constraints {
+			for _, v := range constraint.Check(e.MergedSchedule) {
+				key := violationKey{constraint: i, start: v.Start, end: v.End, message: v.Message}
+				if unresolved[key] {
+					continue
+				}
+				v.Constraint = constraint
+				if cut, ok := e.leastDesirableCut(v.Cuts, rank, merged); ok {
+					e.cut(&v, cut)
+				} else {
+					unresolved[key] = true
+				}
+				violation, found = v, true
+				break search
+			}
+		}
+		if !found {
+			return
+		}
+		e.Violations = append(e.Violations, violation)
+		e.notifyViolation(violation)
+	}
+}
+
+// leastDesirableCut returns the proposed cut of the least desirable event that is not pinned. It reports false if
+// there is no such cut. Cuts of events that are not merged or that do not overlap their event are ignored.
+func (e *Engine) leastDesirableCut(cuts []Cut, rank eventMap[int], merged eventMap[bool]) (Cut, bool) {
+	var (
+		best  Cut
+		found bool
+	)
+	for _, cut := range cuts {
+		original := e.original(cut.Event)
+		if !merged.get(cut.Event) || isPinned(original) || !cut.Start.Before(cut.End) ||
+			!cut.Start.Before(endOf(cut.Event)) || !startOf(cut.Event).Before(cut.End) {
+			continue
+		}
+		if !found || rank.get(original) < rank.get(e.original(best.Event)) {
+			best, found = cut, true
+		}
+	}
+	return best, found
+}
+
+// cut removes the span of the cut from its event, or discards the raw event of the cut altogether if it is not
+// trimmed (see Policy). It records what it did in the violation.
+func (e *Engine) cut(violation *Violation, cut Cut) {
+	original := e.original(cut.Event)
+	if !e.trims(cut.Event) {
+		mergedSchedule := e.MergedSchedule[:0:0]
+		for _, mergedEvent := range e.MergedSchedule {
+			if !sameEvent(e.original(mergedEvent), original) {
+				mergedSchedule = append(mergedSchedule, mergedEvent)
+			}
+		}
+		e.MergedSchedule = mergedSchedule
+		violation.Cut = &Cut{Event: original, Start: exposedBound(startOf(original)), End: exposedBound(endOf(original))}
+		violation.Resolution = Discarded
+		return
+	}
+
+	var (
+		start, end = startOf(cut.Event), endOf(cut.Event)
+		cutStart   = cut.Start
+		cutEnd     = cut.End
+		parts      []Event
+	)
+	if cutStart.Before(start) {
+		cutStart = start
+	}
+	if cutEnd.After(end) {
+		cutEnd = end
+	}
+	if start.Before(cutStart) {
+		part := e.fragment(cut.Event)
+		part.SetEndTime(cutStart.In(boundLocation(end, start)))
+		parts = append(parts, part)
+	}
+	if cutEnd.Before(end) {
+		part := e.fragment(cut.Event)
+		part.SetStartTime(cutEnd.In(boundLocation(start, end)))
+		parts = append(parts, part)
+	}
+
+	for i, mergedEvent := range e.MergedSchedule {
+		if sameEvent(mergedEvent, cut.Event) {
+			// The parts replace the event in place, as the merged schedule is a copy of the merge (see publish).
+			length := len(e.MergedSchedule) + len(parts) - 1
+			if len(parts) > 1 {
+				e.MergedSchedule = append(e.MergedSchedule, parts[1:]...)
+			}
+			copy(e.MergedSchedule[i+len(parts):], e.MergedSchedule[i+1:])
+			copy(e.MergedSchedule[i:], parts)
+			clear(e.MergedSchedule[length:])
+			e.MergedSchedule = e.MergedSchedule[:length]
+			break
+		}
+	}
+	violation.Cut = &Cut{Event: original, Start: exposedBound(cutStart), End: exposedBound(cutEnd)}
+	violation.Resolution = Trimmed
+}
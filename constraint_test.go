@@ -0,0 +1,265 @@
+package scheduleMerge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// noEventsAfter is a Constraint that forbids events after a deadline.
+type noEventsAfter time.Time
+
+func (c noEventsAfter) Check(mergedSchedule []Event) []Violation {
+	deadline := time.Time(c)
+	var violations []Violation
+	for _, event := range mergedSchedule {
+		if end := event.GetEndTime(); end.After(deadline) {
+			violations = append(violations, Violation{
+				Start:   deadline,
+				End:     end,
+				Message: "event after the deadline",
+				Cuts:    []Cut{{Event: event, Start: deadline, End: end}},
+			})
+		}
+	}
+	return violations
+}
+
+func TestEngine_Merge_Constraints(t *testing.T) {
+	origin := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	ev := func(id, start, end int, policy Policy) *policyEvent {
+		return &policyEvent{event: event{StartTime: at(start), EndTime: at(end), ID: id}, Policy: policy}
+	}
+	// span describes a merged event by its ID and bounds.
+	type span struct {
+		ID         int
+		Start, End int
+	}
+	// cut describes the Cut of a Violation by the ID of its event.
+	type cut struct {
+		ID         int
+		Start, End int
+		Resolution Resolution
+	}
+
+	tests := []struct {
+		name         string
+		trimOverlaps bool
+		constraints  []Constraint
+		// raw is sorted by desirability in ascending order.
+		raw      []*policyEvent
+		expected []span
+		cuts     []cut
+	}{
+		{
+			name:         "no violation",
+			trimOverlaps: true,
+			constraints:  []Constraint{MaxDailyDuration{Limit: 8 * time.Hour}},
+			raw:          []*policyEvent{ev(1, 0, 4, PolicyDefault), ev(2, 6, 10, PolicyDefault)},
+			expected:     []span{{1, 0, 4}, {2, 6, 10}},
+		},
+		{
+			name:         "least desirable event is trimmed",
+			trimOverlaps: true,
+			constraints:  []Constraint{MaxDailyDuration{Limit: 8 * time.Hour}},
+			raw:          []*policyEvent{ev(1, 6, 12, PolicyDefault), ev(2, 0, 4, PolicyDefault)},
+			expected:     []span{{2, 0, 4}, {1, 6, 10}},
+			cuts:         []cut{{1, 10, 12, Trimmed}},
+		},
+		{
+			name:         "least desirable event is discarded",
+			trimOverlaps: false,
+			constraints:  []Constraint{MaxDailyDuration{Limit: 8 * time.Hour}},
+			raw:          []*policyEvent{ev(1, 0, 4, PolicyDefault), ev(2, 6, 12, PolicyDefault)},
+			expected:     []span{{2, 6, 12}},
+			cuts:         []cut{{1, 0, 4, Discarded}},
+		},
+		{
+			name:         "atomic event is discarded",
+			trimOverlaps: true,
+			constraints:  []Constraint{MaxDailyDuration{Limit: 8 * time.Hour}},
+			raw:          []*policyEvent{ev(1, 0, 4, PolicyAtomic), ev(2, 6, 12, PolicyDefault)},
+			expected:     []span{{2, 6, 12}},
+			cuts:         []cut{{1, 0, 4, Discarded}},
+		},
+		{
+			name:         "pinned event is not cut",
+			trimOverlaps: true,
+			constraints:  []Constraint{MaxDailyDuration{Limit: 8 * time.Hour}},
+			raw:          []*policyEvent{ev(1, 6, 12, PolicyPinned), ev(2, 0, 4, PolicyDefault)},
+			expected:     []span{{2, 0, 2}, {1, 6, 12}},
+			cuts:         []cut{{2, 2, 4, Trimmed}},
+		},
+		{
+			name:         "violation of pinned events is not resolved",
+			trimOverlaps: true,
+			constraints:  []Constraint{MaxDailyDuration{Limit: 8 * time.Hour}},
+			raw:          []*policyEvent{ev(1, 0, 4, PolicyPinned), ev(2, 6, 12, PolicyPinned)},
+			expected:     []span{{1, 0, 4}, {2, 6, 12}},
+			cuts:         []cut{{}},
+		},
+		{
+			name:         "several cuts",
+			trimOverlaps: true,
+			constraints:  []Constraint{MaxDailyDuration{Limit: 3 * time.Hour}},
+			raw:          []*policyEvent{ev(1, 0, 2, PolicyDefault), ev(2, 3, 5, PolicyDefault), ev(3, 6, 8, PolicyDefault)},
+			expected:     []span{{2, 3, 4}, {3, 6, 8}},
+			cuts:         []cut{{1, 0, 2, Trimmed}, {2, 4, 5, Trimmed}},
+		},
+		{
+			name:         "composed constraints",
+			trimOverlaps: true,
+			constraints: []Constraint{
+				noEventsAfter(at(9)),
+				MinRest{Rest: 3 * time.Hour, MaxBreak: time.Hour},
+			},
+			raw:      []*policyEvent{ev(1, 0, 4, PolicyDefault), ev(2, 6, 10, PolicyDefault)},
+			expected: []span{{1, 0, 3}, {2, 6, 9}},
+			cuts:     []cut{{2, 9, 10, Trimmed}, {1, 3, 4, Trimmed}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := make(orderedSchedule, len(tt.raw))
+			for i, ev := range tt.raw {
+				raw[i] = ev
+			}
+			e := NewEngine(raw, tt.trimOverlaps)
+			e.Constraints = tt.constraints
+			e.Merge()
+
+			var got []span
+			for _, mergedEvent := range e.MergedSchedule {
+				pe := mergedEvent.(*policyEvent)
+				got = append(got, span{ID: pe.ID, Start: int(pe.StartTime.Sub(origin).Hours()), End: int(pe.EndTime.Sub(origin).Hours())})
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("unexpected merged schedule:\n%s", diff)
+			}
+
+			var cuts []cut
+			for _, violation := range e.Violations {
+				if violation.Constraint == nil {
+					t.Errorf("expected the violated constraint to be set")
+				}
+				if violation.Cut == nil {
+					cuts = append(cuts, cut{})
+					continue
+				}
+				cuts = append(cuts, cut{
+					ID:         violation.Cut.Event.(*policyEvent).ID,
+					Start:      int(violation.Cut.Start.Sub(origin).Hours()),
+					End:        int(violation.Cut.End.Sub(origin).Hours()),
+					Resolution: violation.Resolution,
+				})
+			}
+			if diff := cmp.Diff(tt.cuts, cuts); diff != "" {
+				t.Errorf("unexpected cuts:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEngine_Merge_Constraints_Provenance(t *testing.T) {
+	shift := &event{StartTime: at(0), EndTime: at(12), CreatedAt: at(0), ID: 1}
+	e := NewEngine(schedule{shift}, true)
+	e.Constraints = []Constraint{MaxConsecutive{Limit: 5 * time.Hour, MinBreak: time.Hour}}
+	e.Merge()
+
+	expected := []event{
+		{StartTime: at(0), EndTime: at(5), CreatedAt: at(0), ID: 1},
+		{StartTime: at(6), EndTime: at(11), CreatedAt: at(0), ID: 1},
+	}
+	if diff := cmp.Diff(expected, mergedEvents(e.MergedSchedule)); diff != "" {
+		t.Fatalf("unexpected merged schedule:\n%s", diff)
+	}
+	for _, mergedEvent := range e.MergedSchedule {
+		if p, ok := e.Provenance(mergedEvent); !ok || p.Original != shift {
+			t.Fatalf("expected %v to originate from the shift", mergedEvent)
+		}
+	}
+}
+
+func TestEngine_Undo_Constraints(t *testing.T) {
+	e := NewEngine(schedule{{StartTime: at(0), EndTime: at(6), CreatedAt: at(0), ID: 1}}, true)
+	e.Constraints = []Constraint{MaxDailyDuration{Limit: 8 * time.Hour}}
+	e.UndoLimit = 1
+	e.Merge()
+
+	e.Add(&event{StartTime: at(7), EndTime: at(11), CreatedAt: at(1), ID: 2})
+	e.Merge()
+	if len(e.Violations) != 1 {
+		t.Fatalf("expected 1 violation, got %v", e.Violations)
+	}
+
+	e.Undo()
+	if len(e.Violations) != 0 {
+		t.Fatalf("expected no violations after Undo, got %v", e.Violations)
+	}
+}
+
+func TestEngine_MergeParallel_Constraints(t *testing.T) {
+	raw := randomSchedule(42, 200, 24*14)
+	constraints := []Constraint{
+		MaxDailyDuration{Limit: 10 * time.Hour},
+		MinRest{Rest: 11 * time.Hour, MaxBreak: time.Hour},
+		MaxConsecutive{Limit: 6 * time.Hour, MinBreak: 30 * time.Minute},
+	}
+
+	sequential := NewEngine(append(schedule(nil), raw...), true)
+	sequential.Constraints = constraints
+	sequential.Merge()
+
+	parallel := NewEngine(append(schedule(nil), raw...), true)
+	parallel.Constraints = constraints
+	parallel.MergeParallel(4)
+
+	if diff := cmp.Diff(mergedEvents(sequential.MergedSchedule), mergedEvents(parallel.MergedSchedule)); diff != "" {
+		t.Fatalf("unexpected merged schedule:\n%s", diff)
+	}
+	if len(sequential.Violations) == 0 || len(sequential.Violations) != len(parallel.Violations) {
+		t.Fatalf("expected the same violations, got %d and %d", len(sequential.Violations), len(parallel.Violations))
+	}
+	for _, constraint := range constraints {
+		if violations := constraint.Check(sequential.MergedSchedule); len(violations) != 0 {
+			t.Fatalf("expected %T to be satisfied, got %v", constraint, violations)
+		}
+	}
+}
+
+func TestEngine_Add_Constraints(t *testing.T) {
+	raw := randomSchedule(7, 60, 24*3)
+	raw.SortByDesirability()
+	constraints := []Constraint{
+		MaxDailyDuration{Limit: 10 * time.Hour},
+		MinRest{Rest: 11 * time.Hour, MaxBreak: time.Hour},
+	}
+
+	for _, trimOverlaps := range []bool{true, false} {
+		e := NewEngine(orderedSchedule{}, trimOverlaps)
+		e.Constraints = constraints
+		for i, rawEvent := range raw {
+			e.Add(rawEvent)
+			e.Merge()
+
+			// The time cut by the constraints is given back once the events added later no longer need it to be cut.
+			expected := NewEngine(orderedSchedule(raw[:i+1].GetEvents()), trimOverlaps)
+			expected.Constraints = constraints
+			expected.Merge()
+			if diff := cmp.Diff(mergedEvents(expected.MergedSchedule), mergedEvents(e.MergedSchedule)); diff != "" {
+				t.Fatalf("trimOverlaps %v, %d events: unexpected merged schedule:\n%s", trimOverlaps, i+1, diff)
+			}
+			if len(expected.Violations) != len(e.Violations) {
+				t.Fatalf("trimOverlaps %v, %d events: expected %d violations, got %d", trimOverlaps, i+1,
+					len(expected.Violations), len(e.Violations))
+			}
+		}
+
+		merged := mergedEvents(e.MergedSchedule)
+		e.Remerge()
+		if diff := cmp.Diff(mergedEvents(e.MergedSchedule), merged); diff != "" {
+			t.Fatalf("trimOverlaps %v: expected Remerge to change nothing:\n%s", trimOverlaps, diff)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package scheduleMerge
+
+import (
+	"container/heap"
+	"sort"
+	"time"
+)
+
+// OverlapType is how a more desirable event overlaps a less desirable one. The types are the cases the Engine
+// distinguishes when it merges:
+//
+//	1.a  OverlapNoneBefore     more desirable event: [----)
+//	                           less desirable event:       [----)
+//	1.b  OverlapNoneAfter      more desirable event:       [----)
+//	                           less desirable event: [----)
+//	2.a  OverlapPartialStart   more desirable event: [----)
+//	                           less desirable event:    [----)
+//	2.b  OverlapPartialEnd     more desirable event:    [----)
+//	                           less desirable event: [----)
+//	3.a  OverlapEqual          more desirable event: [----)
+//	                           less desirable event: [----)
+//	3.b  OverlapContains       more desirable event: [------)
+//	                           less desirable event:  [----)
+//	3.c  OverlapWithin         more desirable event:  [----)
+//	                           less desirable event: [------)
+//	3.d  OverlapContainsStart  more desirable event: [------)
+//	                           less desirable event: [----)
+//	3.e  OverlapContainsEnd    more desirable event: [------)
+//	                           less desirable event:   [----)
+type OverlapType int
+
+const (
+	OverlapNoneBefore OverlapType = iota + 1
+	OverlapNoneAfter
+	OverlapPartialStart
+	OverlapPartialEnd
+	OverlapEqual
+	OverlapContains
+	OverlapWithin
+	OverlapContainsStart
+	OverlapContainsEnd
+)
+
+// String returns the name of the case of the OverlapType, e.g. "2.a".
+func (o OverlapType) String() string {
+	switch o {
+	case OverlapNoneBefore:
+		return "1.a"
+	case OverlapNoneAfter:
+		return "1.b"
+	case OverlapPartialStart:
+		return "2.a"
+	case OverlapPartialEnd:
+		return "2.b"
+	case OverlapEqual:
+		return "3.a"
+	case OverlapContains:
+		return "3.b"
+	case OverlapWithin:
+		return "3.c"
+	case OverlapContainsStart:
+		return "3.d"
+	case OverlapContainsEnd:
+		return "3.e"
+	default:
+		return "unknown"
+	}
+}
+
+// classifyOverlap returns how the more desirable event [moreStart, moreEnd) overlaps the less desirable event
+// [lessStart, lessEnd).
+func classifyOverlap(moreStart, moreEnd, lessStart, lessEnd time.Time) OverlapType {
+	switch {
+	case !moreStart.Before(lessEnd):
+		return OverlapNoneAfter
+	case !moreEnd.After(lessStart):
+		return OverlapNoneBefore
+	case moreStart.Equal(lessStart) && moreEnd.Equal(lessEnd):
+		return OverlapEqual
+	case !moreStart.After(lessStart) && !moreEnd.Before(lessEnd):
+		// The more desirable event contains the less desirable one.
+		if moreStart.Equal(lessStart) {
+			return OverlapContainsStart
+		}
+		if moreEnd.Equal(lessEnd) {
+			return OverlapContainsEnd
+		}
+		return OverlapContains
+	case !moreStart.Before(lessStart) && !moreEnd.After(lessEnd):
+		return OverlapWithin
+	case moreStart.Before(lessStart):
+		return OverlapPartialStart
+	default:
+		return OverlapPartialEnd
+	}
+}
+
+// Overlap is a pair of overlapping raw events found by DetectConflicts.
+type Overlap struct {
+	// MoreDesirable is the more desirable raw event.
+	MoreDesirable Event
+	// LessDesirable is the less desirable raw event.
+	LessDesirable Event
+	// Type is how MoreDesirable overlaps LessDesirable.
+	Type OverlapType
+	// Start is the start time of the overlap. It is the zero Time if the overlap has no start (see UnboundedEvent).
+	Start time.Time
+	// End is the end time of the overlap. It is the zero Time if the overlap has no end (see UnboundedEvent).
+	End time.Time
+}
+
+// Duration returns the length of the overlap. An overlap without a start or without an end has the maximum
+// Duration.
+func (o Overlap) Duration() time.Duration {
+	if o.Start.IsZero() || o.End.IsZero() {
+		return maxDuration
+	}
+	return o.End.Sub(o.Start)
+}
+
+// Cluster is a group of raw events that overlap each other, directly or through other events of the group.
+type Cluster struct {
+	// Events are the raw events of the cluster, sorted by desirability in ascending order.
+	Events []Event
+	// Start is the start time of the earliest event. It is the zero Time if that event has no start.
+	Start time.Time
+	// End is the end time of the latest event. It is the zero Time if that event has no end.
+	End time.Time
+}
+
+// ConflictReport lists the conflicts of a raw schedule found by DetectConflicts.
+type ConflictReport struct {
+	// Overlaps are all the pairs of overlapping raw events, ordered by the start of the overlap.
+	Overlaps []Overlap
+	// Clusters are the groups of overlapping raw events, ordered by time. Raw events that do not overlap any other
+	// event are not part of a cluster.
+	Clusters []Cluster
+}
+
+// HasConflicts reports whether any raw events overlap.
+func (r ConflictReport) HasConflicts() bool {
+	return len(r.Clusters) > 0
+}
+
+// DetectConflicts sorts the raw schedule by desirability and finds its conflicts without merging it. See
+// Engine.DetectConflicts.
+func DetectConflicts(rawSchedule Schedule) ConflictReport {
+	return NewEngine(rawSchedule, false).DetectConflicts()
+}
+
+// DetectConflicts finds the conflicts of the raw schedule without merging it, e.g. to validate an import before
+// accepting it. It runs in O(n log n + k) time for n raw events and k overlapping pairs. MergedSchedule and Conflicts
+// are not changed.
+func (e *Engine) DetectConflicts() ConflictReport {
+	for _, rawEvent := range e.RawSchedule {
+		e.resolve(rawEvent)
+	}
+
+	// The raw events are swept in the order of their start times, keeping the ones that have not ended yet.
+	ranks := make([]int, len(e.RawSchedule))
+	for i := range ranks {
+		ranks[i] = i
+	}
+	sort.SliceStable(ranks, func(i, j int) bool {
+		return startOf(e.RawSchedule[ranks[i]]).Before(startOf(e.RawSchedule[ranks[j]]))
+	})
+
+	var (
+		report     ConflictReport
+		active     = &endHeap{events: e.RawSchedule}
+		cluster    []int
+		start, end time.Time // The bounds of the cluster.
+	)
+	closeCluster := func() {
+		if len(cluster) > 1 {
+			sort.Ints(cluster)
+			c := Cluster{Start: exposedBound(start), End: exposedBound(end)}
+			for _, rank := range cluster {
+				c.Events = append(c.Events, e.RawSchedule[rank])
+			}
+			report.Clusters = append(report.Clusters, c)
+		}
+		cluster = cluster[:0]
+	}
+
+	for _, rank := range ranks {
+		rawStart := startOf(e.RawSchedule[rank])
+		for active.Len() > 0 && !endOf(e.RawSchedule[active.ranks[0]]).After(rawStart) {
+			heap.Pop(active)
+		}
+		if active.Len() == 0 {
+			closeCluster()
+			start = rawStart
+		}
+
+		for _, other := range active.ranks {
+			more, less := rank, other
+			if more < less {
+				more, less = less, more
+			}
+			report.Overlaps = append(report.Overlaps, e.overlap(more, less))
+		}
+
+		heap.Push(active, rank)
+		if len(cluster) == 0 || endOf(e.RawSchedule[rank]).After(end) {
+			end = endOf(e.RawSchedule[rank])
+		}
+		cluster = append(cluster, rank)
+	}
+	closeCluster()
+
+	rank := rankByEvent(e.RawSchedule)
+	sort.SliceStable(report.Overlaps, func(i, j int) bool {
+		a, b := report.Overlaps[i], report.Overlaps[j]
+		if aStart, bStart := startOfOverlap(a), startOfOverlap(b); !aStart.Equal(bStart) {
+			return aStart.Before(bStart)
+		}
+		if rank.get(a.MoreDesirable) != rank.get(b.MoreDesirable) {
+			return rank.get(a.MoreDesirable) > rank.get(b.MoreDesirable)
+		}
+		return rank.get(a.LessDesirable) > rank.get(b.LessDesirable)
+	})
+	return report
+}
+
+// overlap describes the overlap of the raw events with the given ranks.
+func (e *Engine) overlap(more, less int) Overlap {
+	var (
+		moreEvent, lessEvent = e.RawSchedule[more], e.RawSchedule[less]
+		moreStart, moreEnd   = startOf(moreEvent), endOf(moreEvent)
+		lessStart, lessEnd   = startOf(lessEvent), endOf(lessEvent)
+		start, end           = moreStart, moreEnd
+	)
+	if lessStart.After(start) {
+		start = lessStart
+	}
+	if lessEnd.Before(end) {
+		end = lessEnd
+	}
+
+	return Overlap{
+		MoreDesirable: moreEvent,
+		LessDesirable: lessEvent,
+		Type:          classifyOverlap(moreStart, moreEnd, lessStart, lessEnd),
+		Start:         exposedBound(start),
+		End:           exposedBound(end),
+	}
+}
+
+// startOfOverlap returns the start of the overlap, with a missing start before any other time.
+func startOfOverlap(o Overlap) time.Time {
+	if o.Start.IsZero() {
+		return unboundedStart
+	}
+	return o.Start
+}
+
+// endHeap is a min-heap of the ranks of raw events ordered by their end times.
+type endHeap struct {
+	events []Event
+	ranks  []int
+}
+
+func (h *endHeap) Len() int { return len(h.ranks) }
+func (h *endHeap) Less(i, j int) bool {
+	return endOf(h.events[h.ranks[i]]).Before(endOf(h.events[h.ranks[j]]))
+}
+func (h *endHeap) Swap(i, j int) { h.ranks[i], h.ranks[j] = h.ranks[j], h.ranks[i] }
+func (h *endHeap) Push(x any)    { h.ranks = append(h.ranks, x.(int)) }
+func (h *endHeap) Pop() any {
+	rank := h.ranks[len(h.ranks)-1]
+	h.ranks = h.ranks[:len(h.ranks)-1]
+	return rank
+}
@@ -0,0 +1,118 @@
+package scheduleMerge
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestClassifyOverlap(t *testing.T) {
+	tests := []struct {
+		more, less [2]int
+		expected   OverlapType
+	}{
+		{more: [2]int{0, 2}, less: [2]int{2, 4}, expected: OverlapNoneBefore},
+		{more: [2]int{2, 4}, less: [2]int{0, 2}, expected: OverlapNoneAfter},
+		{more: [2]int{0, 2}, less: [2]int{1, 3}, expected: OverlapPartialStart},
+		{more: [2]int{1, 3}, less: [2]int{0, 2}, expected: OverlapPartialEnd},
+		{more: [2]int{0, 2}, less: [2]int{0, 2}, expected: OverlapEqual},
+		{more: [2]int{0, 4}, less: [2]int{1, 3}, expected: OverlapContains},
+		{more: [2]int{1, 3}, less: [2]int{0, 4}, expected: OverlapWithin},
+		{more: [2]int{0, 3}, less: [2]int{0, 4}, expected: OverlapWithin},
+		{more: [2]int{1, 4}, less: [2]int{0, 4}, expected: OverlapWithin},
+		{more: [2]int{0, 4}, less: [2]int{0, 2}, expected: OverlapContainsStart},
+		{more: [2]int{0, 4}, less: [2]int{2, 4}, expected: OverlapContainsEnd},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v-%v", tt.more, tt.less), func(t *testing.T) {
+			got := classifyOverlap(at(tt.more[0]), at(tt.more[1]), at(tt.less[0]), at(tt.less[1]))
+			if got != tt.expected {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDetectConflicts(t *testing.T) {
+	var (
+		a = &event{StartTime: at(0), EndTime: at(4), CreatedAt: at(0), ID: 1}
+		b = &event{StartTime: at(3), EndTime: at(6), CreatedAt: at(1), ID: 2}
+		c = &event{StartTime: at(5), EndTime: at(7), CreatedAt: at(2), ID: 3}
+		d = &event{StartTime: at(7), EndTime: at(8), CreatedAt: at(3), ID: 4}
+		e = &event{StartTime: at(9), EndTime: at(12), CreatedAt: at(4), ID: 5}
+		f = &event{StartTime: at(10), EndTime: at(11), CreatedAt: at(5), ID: 6}
+	)
+	report := DetectConflicts(schedule{f, e, d, c, b, a})
+
+	expectedOverlaps := []Overlap{
+		{MoreDesirable: b, LessDesirable: a, Type: OverlapPartialEnd, Start: at(3), End: at(4)},
+		{MoreDesirable: c, LessDesirable: b, Type: OverlapPartialEnd, Start: at(5), End: at(6)},
+		{MoreDesirable: f, LessDesirable: e, Type: OverlapWithin, Start: at(10), End: at(11)},
+	}
+	if diff := cmp.Diff(expectedOverlaps, report.Overlaps); diff != "" {
+		t.Fatalf("unexpected overlaps:\n%s", diff)
+	}
+	if got := report.Overlaps[0].Duration(); got != time.Hour {
+		t.Fatalf("expected the overlap to last an hour, got %v", got)
+	}
+
+	// d only touches c, so it is not part of the cluster.
+	expectedClusters := []Cluster{
+		{Events: []Event{a, b, c}, Start: at(0), End: at(7)},
+		{Events: []Event{e, f}, Start: at(9), End: at(12)},
+	}
+	if diff := cmp.Diff(expectedClusters, report.Clusters); diff != "" {
+		t.Fatalf("unexpected clusters:\n%s", diff)
+	}
+	if !report.HasConflicts() {
+		t.Fatalf("expected conflicts")
+	}
+
+	if report := DetectConflicts(schedule{a, d}); report.HasConflicts() || len(report.Overlaps) != 0 {
+		t.Fatalf("expected no conflicts, got %v", report)
+	}
+}
+
+func TestEngine_DetectConflicts(t *testing.T) {
+	// Every overlapping pair is found, and its classification is the one merging uses.
+	for seed := int64(1); seed <= 20; seed++ {
+		t.Run(fmt.Sprintf("seed %d", seed), func(t *testing.T) {
+			e := NewEngine(randomSchedule(seed, 200, 100), false)
+			report := e.DetectConflicts()
+
+			var expected []string
+			for more := range e.RawSchedule {
+				for less := 0; less < more; less++ {
+					overlap := classifyOverlap(startOf(e.RawSchedule[more]), endOf(e.RawSchedule[more]),
+						startOf(e.RawSchedule[less]), endOf(e.RawSchedule[less]))
+					if overlap != OverlapNoneBefore && overlap != OverlapNoneAfter {
+						expected = append(expected, fmt.Sprintf("%d>%d %v", more, less, overlap))
+					}
+				}
+			}
+
+			rank := map[Event]int{}
+			for i, rawEvent := range e.RawSchedule {
+				rank[rawEvent] = i
+			}
+			got := map[string]bool{}
+			for _, overlap := range report.Overlaps {
+				got[fmt.Sprintf("%d>%d %v", rank[overlap.MoreDesirable], rank[overlap.LessDesirable], overlap.Type)] = true
+			}
+			if len(got) != len(expected) || len(report.Overlaps) != len(expected) {
+				t.Fatalf("expected %d overlaps, got %d", len(expected), len(report.Overlaps))
+			}
+			for _, overlap := range expected {
+				if !got[overlap] {
+					t.Fatalf("expected overlap %s", overlap)
+				}
+			}
+
+			if len(e.MergedSchedule) != 0 || len(e.Conflicts) != 0 {
+				t.Fatalf("expected the engine not to merge")
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package scheduleMerge
+
+import (
+	"sort"
+)
+
+// IdentifiableEvent is an optional interface for Event(s) that keep an identity across merges, e.g. the ID of the
+// event in an external calendar. Diff uses it to tell a resized event from an event that was removed and another one
+// that was added.
+//
+// All the parts of a trimmed IdentifiableEvent share its identity, as Clone copies it.
+type IdentifiableEvent interface {
+	Event
+	// GetID returns the identity of the Event.
+	GetID() string
+}
+
+// ChangeSet is the difference between two merged schedules. Each of its slices is ordered by the start time of the
+// events.
+type ChangeSet struct {
+	// Added holds the events of the current merged schedule that have no counterpart in the previous one.
+	Added []Event
+	// Removed holds the events of the previous merged schedule that have no counterpart in the current one.
+	Removed []Event
+	// Resized holds the events whose start or end time changed.
+	Resized []Resize
+}
+
+// Resize is an event of the previous merged schedule that corresponds to an event of the current merged schedule
+// with different bounds.
+type Resize struct {
+	// Previous is the event of the previous merged schedule.
+	Previous Event
+	// Current is the event of the current merged schedule.
+	Current Event
+}
+
+// IsEmpty reports whether the merged schedules are the same.
+func (c ChangeSet) IsEmpty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Resized) == 0
+}
+
+// Diff returns the changes that turn the previous merged schedule into the current one, e.g. to send only these
+// changes to an external calendar.
+//
+// Events correspond to each other if they have the same identity and overlap. The identity of an IdentifiableEvent is
+// its ID; any other event is only identical to itself, so a part of it that was trimmed again shows up as removed and
+// added. Snapshot.Diff also knows which raw event every part originates from.
+func Diff(previous, current []Event) ChangeSet {
+	return diff(previous, current, identityOf, identityOf)
+}
+
+// Diff returns the changes that turn the merged schedule of the previous Snapshot into the one of s. The parts of the
+// same raw event correspond to each other even if the event is not an IdentifiableEvent. See Diff.
+func (s *Snapshot) Diff(previous *Snapshot) ChangeSet {
+	return diff(previous.MergedSchedule, s.MergedSchedule, previous.identityOf, s.identityOf)
+}
+
+// identityOf returns the ID of an IdentifiableEvent and the key of the event otherwise (see eventKey).
+func identityOf(event Event) any {
+	if identifiable, ok := event.(IdentifiableEvent); ok {
+		return identifiable.GetID()
+	}
+	if key, ok := eventKey(event); ok {
+		return key
+	}
+	// An event without a key is not identical to any other event, not even to itself in the other schedule.
+	return &event
+}
+
+// identityOf returns the ID of an IdentifiableEvent and the raw event the event originates from otherwise.
+func (s *Snapshot) identityOf(event Event) any {
+	if origin, ok := s.origins.lookup(event); ok {
+		event = origin
+	}
+	return identityOf(event)
+}
+
+// diff matches the events of both merged schedules by their identity. The parts of the same identity do not overlap
+// each other, so they are matched in time order: overlapping parts correspond to each other, and a part that does not
+// overlap the next part of the other schedule was added or removed.
+func diff(previous, current []Event, previousIdentity, currentIdentity func(Event) any) ChangeSet {
+	var (
+		identities []any
+		previousOf = map[any][]Event{}
+		currentOf  = map[any][]Event{}
+	)
+	for _, event := range previous {
+		identity := previousIdentity(event)
+		if _, ok := previousOf[identity]; !ok {
+			identities = append(identities, identity)
+		}
+		previousOf[identity] = append(previousOf[identity], event)
+	}
+	for _, event := range current {
+		identity := currentIdentity(event)
+		if _, ok := previousOf[identity]; !ok {
+			if _, ok := currentOf[identity]; !ok {
+				identities = append(identities, identity)
+			}
+		}
+		currentOf[identity] = append(currentOf[identity], event)
+	}
+
+	var changes ChangeSet
+	for _, identity := range identities {
+		previousParts, currentParts := previousOf[identity], currentOf[identity]
+		i, j := 0, 0
+		for i < len(previousParts) && j < len(currentParts) {
+			p, c := previousParts[i], currentParts[j]
+			switch {
+			case startOf(p).Before(endOf(c)) && startOf(c).Before(endOf(p)):
+				if !startOf(p).Equal(startOf(c)) || !endOf(p).Equal(endOf(c)) {
+					changes.Resized = append(changes.Resized, Resize{Previous: p, Current: c})
+				}
+				i++
+				j++
+			case !endOf(p).After(startOf(c)):
+				changes.Removed = append(changes.Removed, p)
+				i++
+			default:
+				changes.Added = append(changes.Added, c)
+				j++
+			}
+		}
+		changes.Removed = append(changes.Removed, previousParts[i:]...)
+		changes.Added = append(changes.Added, currentParts[j:]...)
+	}
+
+	sortByStart(changes.Added)
+	sortByStart(changes.Removed)
+	sort.SliceStable(changes.Resized, func(i, j int) bool {
+		return startOf(changes.Resized[i].Current).Before(startOf(changes.Resized[j].Current))
+	})
+	return changes
+}
+
+// sortByStart sorts the events by their start time.
+func sortByStart(events []Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return startOf(events[i]).Before(startOf(events[j]))
+	})
+}
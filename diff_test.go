@@ -0,0 +1,137 @@
+package scheduleMerge
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type identifiableEvent struct {
+	event
+}
+
+func (e *identifiableEvent) GetID() string {
+	return strconv.Itoa(e.ID)
+}
+
+func (e *identifiableEvent) Clone() Event {
+	clone := *e
+	return &clone
+}
+
+func TestDiff(t *testing.T) {
+	origin := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	ev := func(id, start, end int) Event {
+		return &identifiableEvent{event: event{StartTime: at(start), EndTime: at(end), ID: id}}
+	}
+	// span describes an event of a change set by its ID and bounds.
+	type span struct {
+		ID         int
+		Start, End int
+	}
+	spans := func(events []Event) []span {
+		var s []span
+		for _, e := range events {
+			ie := e.(*identifiableEvent)
+			s = append(s, span{ID: ie.ID, Start: int(ie.StartTime.Sub(origin).Hours()), End: int(ie.EndTime.Sub(origin).Hours())})
+		}
+		return s
+	}
+
+	tests := []struct {
+		name              string
+		previous, current []Event
+		added, removed    []span
+		resized           [][2]span
+	}{
+		{
+			name:     "no changes",
+			previous: []Event{ev(1, 0, 2), ev(2, 2, 4)},
+			current:  []Event{ev(1, 0, 2), ev(2, 2, 4)},
+		},
+		{
+			name:     "added and removed",
+			previous: []Event{ev(1, 0, 2), ev(2, 2, 4)},
+			current:  []Event{ev(1, 0, 2), ev(3, 5, 6)},
+			added:    []span{{3, 5, 6}},
+			removed:  []span{{2, 2, 4}},
+		},
+		{
+			name:     "resized",
+			previous: []Event{ev(1, 0, 4)},
+			current:  []Event{ev(1, 0, 2), ev(2, 2, 4)},
+			added:    []span{{2, 2, 4}},
+			resized:  [][2]span{{{1, 0, 4}, {1, 0, 2}}},
+		},
+		{
+			name:     "split into two parts",
+			previous: []Event{ev(1, 0, 6)},
+			current:  []Event{ev(1, 0, 2), ev(2, 2, 4), ev(1, 4, 6)},
+			added:    []span{{2, 2, 4}, {1, 4, 6}},
+			resized:  [][2]span{{{1, 0, 6}, {1, 0, 2}}},
+		},
+		{
+			name:     "moved without overlapping",
+			previous: []Event{ev(1, 0, 2)},
+			current:  []Event{ev(1, 3, 4)},
+			added:    []span{{1, 3, 4}},
+			removed:  []span{{1, 0, 2}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := Diff(tt.previous, tt.current)
+			if diff := cmp.Diff(tt.added, spans(changes.Added)); diff != "" {
+				t.Errorf("unexpected added events:\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.removed, spans(changes.Removed)); diff != "" {
+				t.Errorf("unexpected removed events:\n%s", diff)
+			}
+			var resized [][2]span
+			for _, r := range changes.Resized {
+				resized = append(resized, [2]span{spans([]Event{r.Previous})[0], spans([]Event{r.Current})[0]})
+			}
+			if diff := cmp.Diff(tt.resized, resized); diff != "" {
+				t.Errorf("unexpected resized events:\n%s", diff)
+			}
+			if expected := len(tt.added)+len(tt.removed)+len(tt.resized) == 0; changes.IsEmpty() != expected {
+				t.Errorf("expected IsEmpty to be %t", expected)
+			}
+		})
+	}
+}
+
+func TestSnapshot_Diff(t *testing.T) {
+	origin := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	long := &event{StartTime: origin, EndTime: origin.Add(6 * time.Hour), ID: 1}
+	e := NewEngine(schedule{long}, true)
+	e.Merge()
+	previous := e.Snapshot()
+
+	// The parts of the long event that are left are not IdentifiableEvent(s), but still correspond to it.
+	middle := &event{StartTime: origin.Add(2 * time.Hour), EndTime: origin.Add(4 * time.Hour), ID: 2}
+	e.Add(middle)
+	e.Merge()
+	current := e.Snapshot()
+
+	changes := current.Diff(previous)
+	if len(changes.Added) != 2 || changes.Added[0] != middle || e.original(changes.Added[1]) != long {
+		t.Fatalf("expected the middle event and the end of the long event to be added, got %v", changes.Added)
+	}
+	if len(changes.Removed) != 0 {
+		t.Fatalf("expected no removed events, got %v", changes.Removed)
+	}
+	if len(changes.Resized) != 1 || changes.Resized[0].Previous != long || e.original(changes.Resized[0].Current) != long {
+		t.Fatalf("expected the long event to be resized, got %v", changes.Resized)
+	}
+
+	if changes := current.Diff(current); !changes.IsEmpty() {
+		t.Fatalf("expected no changes, got %v", changes)
+	}
+	// Without the origins, the parts of the long event do not correspond to it.
+	if changes := Diff(previous.MergedSchedule, current.MergedSchedule); len(changes.Removed) != 1 {
+		t.Fatalf("expected the long event to be removed, got %v", changes.Removed)
+	}
+}
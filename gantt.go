@@ -0,0 +1,214 @@
+package scheduleMerge
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"time"
+)
+
+const (
+	defaultGanttWidth      = 800
+	defaultGanttLaneHeight = 24
+	ganttLabelWidth        = 120
+	ganttAxisHeight        = 30
+	ganttLegendHeight      = 30
+	ganttTicks             = 6
+)
+
+// ganttStyle is embedded into every exported chart so that it does not depend on any external resource.
+const ganttStyle = `
+text { font-family: sans-serif; font-size: 11px; fill: #24292f; }
+.axis { stroke: #8c959f; stroke-width: 1; }
+.raw { fill: #eaeef2; stroke: #8c959f; stroke-width: 1; }
+.kept { fill: #2da44e; }
+.trimmed { fill: #fb8500; }
+.discarded { fill: #cf222e; }
+.merged { fill: #0969da; stroke: #ffffff; stroke-width: 1; }
+`
+
+// GanttOptions configures the charts written by WriteSVG and WriteHTML.
+type GanttOptions struct {
+	// Title is shown above the chart. It is optional.
+	Title string
+	// Width is the width of the time axis in pixels. Defaults to 800.
+	Width int
+	// LaneHeight is the height of a single lane in pixels. Defaults to 24.
+	LaneHeight int
+	// Label returns the label of a raw event's lane. Defaults to "raw N", where N is the position of the event in
+	// the raw schedule.
+	Label func(Event) string
+}
+
+// WriteSVG writes the result of the merge as a Gantt chart in the SVG format. Every raw event gets its own lane,
+// with the most desirable event on the top. The parts of a raw event that made it into the merged schedule are
+// highlighted, as are the parts that were trimmed or discarded because of a Conflict. The last lane shows the merged
+// schedule.
+//
+// The chart is self-contained: it has no scripts and does not reference any external resource.
+func (e *Engine) WriteSVG(w io.Writer, opts GanttOptions) error {
+	ew := &errWriter{w: w}
+	e.writeGantt(ew, opts)
+	return ew.err
+}
+
+// WriteHTML writes the chart produced by WriteSVG embedded into a self-contained HTML document.
+func (e *Engine) WriteHTML(w io.Writer, opts GanttOptions) error {
+	ew := &errWriter{w: w}
+	title := opts.Title
+	if title == "" {
+		title = "Merged schedule"
+	}
+
+	ew.printf("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n", html.EscapeString(title))
+	ew.printf("<style>body { margin: 2em; font-family: sans-serif; }</style>\n</head>\n<body>\n")
+	ew.printf("<h1>%s</h1>\n", html.EscapeString(title))
+	// The title is already shown as the heading of the document.
+	opts.Title = ""
+	e.writeGantt(ew, opts)
+	ew.printf("</body>\n</html>\n")
+	return ew.err
+}
+
+// writeGantt draws the Gantt chart of the engine as an SVG element.
+func (e *Engine) writeGantt(ew *errWriter, opts GanttOptions) {
+	width := opts.Width
+	if width <= 0 {
+		width = defaultGanttWidth
+	}
+	laneHeight := opts.LaneHeight
+	if laneHeight <= 0 {
+		laneHeight = defaultGanttLaneHeight
+	}
+	label := opts.Label
+	if label == nil {
+		rank := rankByEvent(e.RawSchedule)
+		label = func(event Event) string {
+			return fmt.Sprintf("raw %d", rank.get(event))
+		}
+	}
+
+	top := ganttAxisHeight
+	if opts.Title != "" {
+		top += laneHeight
+	}
+	lanes := len(e.RawSchedule) + 1
+	totalWidth := ganttLabelWidth + width + 10
+	totalHeight := top + lanes*laneHeight + ganttLegendHeight
+
+	origin, end := timelineBounds(e.RawSchedule, e.MergedSchedule)
+	span := end.Sub(origin)
+	x := func(t time.Time) float64 {
+		// The missing sides of UnboundedEvent(s) extend to the edges of the chart.
+		switch {
+		case span <= 0 || !t.After(origin):
+			return ganttLabelWidth
+		case t.After(end):
+			return float64(ganttLabelWidth + width)
+		}
+		return ganttLabelWidth + float64(t.Sub(origin))/float64(span)*float64(width)
+	}
+	bar := func(class string, from, to time.Time, y int, height int, tooltip string) {
+		ew.printf(`<rect class="%s" x="%.2f" y="%d" width="%.2f" height="%d"><title>%s</title></rect>`+"\n",
+			class, x(from), y, x(to)-x(from), height, html.EscapeString(tooltip))
+	}
+
+	ew.printf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		totalWidth, totalHeight, totalWidth, totalHeight)
+	ew.printf("<style>%s</style>\n", ganttStyle)
+	if opts.Title != "" {
+		ew.printf(`<text x="0" y="%d" style="font-size: 14px; font-weight: bold;">%s</text>`+"\n",
+			laneHeight-8, html.EscapeString(opts.Title))
+	}
+
+	// Time axis.
+	axisY := top - 10
+	ew.printf(`<line class="axis" x1="%d" y1="%d" x2="%d" y2="%d"/>`+"\n", ganttLabelWidth, axisY, ganttLabelWidth+width, axisY)
+	for tick := 0; tick <= ganttTicks; tick++ {
+		t := origin.Add(span * time.Duration(tick) / ganttTicks)
+		ew.printf(`<line class="axis" x1="%.2f" y1="%d" x2="%.2f" y2="%d"/>`+"\n", x(t), axisY-4, x(t), top+lanes*laneHeight)
+		ew.printf(`<text x="%.2f" y="%d" text-anchor="middle">%s</text>`+"\n",
+			x(t), axisY-6, html.EscapeString(t.Format("2006-01-02 15:04")))
+	}
+
+	// Which parts of which raw event were kept and which were lost.
+	kept := eventMap[[]Event]{}
+	for _, mergedEvent := range e.MergedSchedule {
+		rawEvent := e.original(mergedEvent)
+		kept.set(rawEvent, append(kept.get(rawEvent), mergedEvent))
+	}
+	lost := eventMap[[]Conflict]{}
+	for _, conflict := range e.Conflicts {
+		lost.set(conflict.Loser, append(lost.get(conflict.Loser), conflict))
+	}
+
+	barHeight := laneHeight * 2 / 3
+	y := top
+	for i := len(e.RawSchedule) - 1; i >= 0; i-- {
+		rawEvent := e.RawSchedule[i]
+		rawLabel := label(rawEvent)
+		barY := y + (laneHeight-barHeight)/2
+
+		ew.printf(`<text x="0" y="%d">%s</text>`+"\n", barY+barHeight-3, html.EscapeString(rawLabel))
+		bar("raw", startOf(rawEvent), endOf(rawEvent), barY, barHeight,
+			fmt.Sprintf("%s: %s", rawLabel, formatGanttSpan(startOf(rawEvent), endOf(rawEvent))))
+		for _, part := range kept.get(rawEvent) {
+			bar("kept", startOf(part), endOf(part), barY, barHeight,
+				fmt.Sprintf("%s kept: %s", rawLabel, formatGanttSpan(startOf(part), endOf(part))))
+		}
+		for _, conflict := range lost.get(rawEvent) {
+			conflictStart, conflictEnd := conflict.Start, conflict.End
+			if conflictEnd.IsZero() {
+				conflictEnd = unboundedEnd
+			}
+			bar(conflict.Resolution.String(), conflictStart, conflictEnd, barY, barHeight,
+				fmt.Sprintf("%s %s by %s: %s", rawLabel, conflict.Resolution, label(conflict.Winner),
+					formatGanttSpan(conflictStart, conflictEnd)))
+		}
+		y += laneHeight
+	}
+
+	barY := y + (laneHeight-barHeight)/2
+	ew.printf(`<text x="0" y="%d" style="font-weight: bold;">merged</text>`+"\n", barY+barHeight-3)
+	for _, mergedEvent := range e.MergedSchedule {
+		bar("merged", startOf(mergedEvent), endOf(mergedEvent), barY, barHeight,
+			fmt.Sprintf("%s: %s", label(e.original(mergedEvent)),
+				formatGanttSpan(startOf(mergedEvent), endOf(mergedEvent))))
+	}
+	y += laneHeight
+
+	// Legend.
+	legendX := ganttLabelWidth
+	for _, class := range []string{"raw", "kept", "trimmed", "discarded", "merged"} {
+		ew.printf(`<rect class="%s" x="%d" y="%d" width="12" height="12"/>`+"\n", class, legendX, y+10)
+		ew.printf(`<text x="%d" y="%d">%s</text>`+"\n", legendX+16, y+20, class)
+		legendX += 90
+	}
+
+	ew.printf("</svg>\n")
+}
+
+// formatGanttSpan formats the [start, end) span shown in the tooltips of a Gantt chart. Missing bounds are shown as
+// an ellipsis.
+func formatGanttSpan(start, end time.Time) string {
+	format := func(t time.Time) string {
+		if t.IsZero() || isUnbounded(t) {
+			return "…"
+		}
+		return t.Format(time.RFC3339)
+	}
+	return fmt.Sprintf("[%s, %s)", format(start), format(end))
+}
+
+// errWriter remembers the first error returned by the underlying writer and skips all subsequent writes.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, args ...any) {
+	if ew.err != nil {
+		return
+	}
+	_, ew.err = fmt.Fprintf(ew.w, format, args...)
+}
@@ -0,0 +1,109 @@
+package scheduleMerge
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEngine_WriteSVG(t *testing.T) {
+	// less desirable event: [------------)
+	// more desirable event:     [----)
+	e := NewEngine(schedule{
+		{
+			StartTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2020, 1, 1, 6, 0, 0, 0, time.UTC),
+			CreatedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			ID:        1,
+		},
+		{
+			StartTime: time.Date(2020, 1, 1, 2, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2020, 1, 1, 4, 0, 0, 0, time.UTC),
+			CreatedAt: time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC),
+			ID:        2,
+		},
+	}, true)
+	e.Merge()
+
+	var buf bytes.Buffer
+	if err := e.WriteSVG(&buf, GanttOptions{Title: "Rota <draft>"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	classes := map[string]int{}
+	decoder := xml.NewDecoder(bytes.NewReader(buf.Bytes()))
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid SVG: %v\n%s", err, buf.String())
+		}
+		if element, ok := token.(xml.StartElement); ok && element.Name.Local == "rect" {
+			for _, attr := range element.Attr {
+				if attr.Name.Local == "class" {
+					classes[attr.Value]++
+				}
+			}
+		}
+	}
+
+	// 2 raw lanes, 3 kept parts (both parts of event 1 and event 2), 1 trimmed overlap and 3 merged events, each
+	// plus one legend entry.
+	expected := map[string]int{"raw": 3, "kept": 4, "trimmed": 2, "discarded": 1, "merged": 4}
+	for class, count := range expected {
+		if classes[class] != count {
+			t.Errorf("expected %d %q rects, got %d", count, class, classes[class])
+		}
+	}
+
+	svg := buf.String()
+	if !strings.Contains(svg, "Rota &lt;draft&gt;") {
+		t.Errorf("expected the escaped title in the SVG:\n%s", svg)
+	}
+	if strings.Contains(svg, "<script") || strings.Count(svg, "http") != 1 {
+		t.Errorf("expected a self-contained SVG without scripts or external resources:\n%s", svg)
+	}
+}
+
+func TestEngine_WriteHTML(t *testing.T) {
+	e := NewEngine(schedule{
+		{
+			StartTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC),
+			CreatedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			ID:        1,
+		},
+	}, false)
+	e.Merge()
+
+	var buf bytes.Buffer
+	if err := e.WriteHTML(&buf, GanttOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	page := buf.String()
+	for _, part := range []string{"<!DOCTYPE html>", "<h1>Merged schedule</h1>", "<svg ", "</svg>", "</html>"} {
+		if !strings.Contains(page, part) {
+			t.Errorf("expected %q in the HTML document:\n%s", part, page)
+		}
+	}
+	if strings.Contains(page, "<script") || strings.Contains(page, "<link") {
+		t.Errorf("expected a self-contained HTML document:\n%s", page)
+	}
+
+	if err := e.WriteHTML(failingWriter{}, GanttOptions{}); err == nil {
+		t.Errorf("expected the error of the writer to be returned")
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
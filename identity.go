@@ -0,0 +1,90 @@
+package scheduleMerge
+
+import (
+	"reflect"
+)
+
+// eventKey returns a comparable key that identifies the event, to look it up in maps and to compare it with other
+// events. Events of comparable types, such as pointers to structs, are their own keys. Comparing or hashing an Event of
+// any other type panics, so maps and slices are keyed by the data they refer to instead. eventKey reports false for the
+// remaining types (e.g. structs holding a slice), whose values cannot be told apart from their copies.
+func eventKey(event Event) (any, bool) {
+	t := reflect.TypeOf(event)
+	if t == nil || t.Comparable() {
+		return event, true
+	}
+
+	v := reflect.ValueOf(event)
+	switch v.Kind() {
+	case reflect.Map:
+		return referenceKey{typ: t, pointer: v.Pointer()}, true
+	case reflect.Slice:
+		return referenceKey{typ: t, pointer: v.Pointer(), len: v.Len()}, true
+	default:
+		return nil, false
+	}
+}
+
+// referenceKey is the key of an Event of a map or slice type, which cannot be compared.
+type referenceKey struct {
+	typ     reflect.Type
+	pointer uintptr
+	len     int
+}
+
+// sameEvent reports whether a and b are the same event. Events without a key (see eventKey) are not the same as any
+// event.
+func sameEvent(a, b Event) bool {
+	aKey, ok := eventKey(a)
+	if !ok {
+		return false
+	}
+	bKey, ok := eventKey(b)
+	return ok && aKey == bKey
+}
+
+// eventMap maps events to values by their keys (see eventKey). Events without a key are never found in it.
+type eventMap[V any] map[any]V
+
+// get returns the value of the event, or the zero value if the event is not found.
+func (m eventMap[V]) get(event Event) V {
+	value, _ := m.lookup(event)
+	return value
+}
+
+// lookup returns the value of the event and reports whether the event was found.
+func (m eventMap[V]) lookup(event Event) (V, bool) {
+	key, ok := eventKey(event)
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	value, ok := m[key]
+	return value, ok
+}
+
+// set sets the value of the event. It does nothing for events without a key.
+func (m eventMap[V]) set(event Event, value V) {
+	if key, ok := eventKey(event); ok {
+		m[key] = value
+	}
+}
+
+// rankByEvent maps every event to its index in events.
+func rankByEvent(events []Event) eventMap[int] {
+	rank := make(eventMap[int], len(events))
+	for i, event := range events {
+		rank.set(event, i)
+	}
+	return rank
+}
+
+// allKeyed reports whether every event has a key (see eventKey).
+func allKeyed(events []Event) bool {
+	for _, event := range events {
+		if _, ok := eventKey(event); !ok {
+			return false
+		}
+	}
+	return true
+}
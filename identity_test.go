@@ -0,0 +1,173 @@
+package scheduleMerge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// mapEvent is an Event of a type that cannot be compared, so it cannot be a map key either.
+type mapEvent map[string]time.Time
+
+func (e mapEvent) GetStartTime() time.Time  { return e["start"] }
+func (e mapEvent) GetEndTime() time.Time    { return e["end"] }
+func (e mapEvent) SetStartTime(t time.Time) { e["start"] = t }
+func (e mapEvent) SetEndTime(t time.Time)   { e["end"] = t }
+
+func (e mapEvent) Clone() Event {
+	clone := make(mapEvent, len(e))
+	for k, v := range e {
+		clone[k] = v
+	}
+	return clone
+}
+
+// sliceEvent is an Event of a type that cannot be compared and whose copies cannot be told apart.
+type sliceEvent struct {
+	bounds []time.Time
+}
+
+func (e sliceEvent) GetStartTime() time.Time  { return e.bounds[0] }
+func (e sliceEvent) GetEndTime() time.Time    { return e.bounds[1] }
+func (e sliceEvent) SetStartTime(t time.Time) { e.bounds[0] = t }
+func (e sliceEvent) SetEndTime(t time.Time)   { e.bounds[1] = t }
+
+func (e sliceEvent) Clone() Event {
+	return sliceEvent{bounds: append([]time.Time(nil), e.bounds...)}
+}
+
+// meetingEvent is a ParticipantEvent of a type that cannot be compared.
+type meetingEvent map[string]any
+
+func (e meetingEvent) GetStartTime() time.Time   { return e["start"].(time.Time) }
+func (e meetingEvent) GetEndTime() time.Time     { return e["end"].(time.Time) }
+func (e meetingEvent) SetStartTime(t time.Time)  { e["start"] = t }
+func (e meetingEvent) SetEndTime(t time.Time)    { e["end"] = t }
+func (e meetingEvent) GetParticipants() []string { return e["participants"].([]string) }
+
+func (e meetingEvent) Clone() Event {
+	clone := make(meetingEvent, len(e))
+	for k, v := range e {
+		clone[k] = v
+	}
+	return clone
+}
+
+// convertEvents returns the raw schedule, sorted by desirability, with every event converted.
+func convertEvents(raw schedule, convert func(*event) Event) orderedSchedule {
+	raw.SortByDesirability()
+	converted := make(orderedSchedule, len(raw))
+	for i, e := range raw {
+		converted[i] = convert(e)
+	}
+	return converted
+}
+
+// spans returns the bounds of the events.
+func spans(events []Event) [][2]time.Time {
+	result := make([][2]time.Time, len(events))
+	for i, e := range events {
+		result[i] = [2]time.Time{startOf(e), endOf(e)}
+	}
+	return result
+}
+
+func TestEngine_Merge_UncomparableEvents(t *testing.T) {
+	toMap := func(e *event) Event { return mapEvent{"start": e.StartTime, "end": e.EndTime} }
+	toSlice := func(e *event) Event { return sliceEvent{bounds: []time.Time{e.StartTime, e.EndTime}} }
+	refined := func(e *Engine) {
+		e.Granularity = time.Hour
+		e.MinFragment = 2 * time.Hour
+		e.Constraints = []Constraint{MinRest{Rest: time.Hour, MaxBreak: -1}}
+	}
+
+	tests := []struct {
+		name      string
+		convert   func(*event) Event
+		configure func(*Engine)
+	}{
+		{name: "map", convert: toMap},
+		{name: "map with refined parts and constraints", convert: toMap, configure: refined},
+		// The parts of slice events are not traced back to their raw events, so only the plain merge applies to them.
+		{name: "slice", convert: toSlice},
+	}
+	for _, tt := range tests {
+		for _, trimOverlaps := range []bool{true, false} {
+			for _, parallel := range []bool{false, true} {
+				expected := NewEngine(convertEvents(randomSchedule(1, 300, 200), func(e *event) Event { return e }), trimOverlaps)
+				e := NewEngine(convertEvents(randomSchedule(1, 300, 200), tt.convert), trimOverlaps)
+				if tt.configure != nil {
+					tt.configure(expected)
+					tt.configure(e)
+				}
+				expected.Merge()
+				if parallel {
+					e.MergeParallel(4)
+				} else {
+					e.Merge()
+				}
+
+				if diff := cmp.Diff(spans(expected.MergedSchedule), spans(e.MergedSchedule)); diff != "" {
+					t.Fatalf("%s, trimOverlaps %v, parallel %v: unexpected merged schedule:\n%s",
+						tt.name, trimOverlaps, parallel, diff)
+				}
+				if len(expected.Conflicts) != len(e.Conflicts) || len(expected.Violations) != len(e.Violations) {
+					t.Fatalf("%s, trimOverlaps %v, parallel %v: expected %d conflicts and %d violations, got %d and %d",
+						tt.name, trimOverlaps, parallel, len(expected.Conflicts), len(expected.Violations),
+						len(e.Conflicts), len(e.Violations))
+				}
+			}
+		}
+	}
+}
+
+func TestEngine_UncomparableEvents_Identity(t *testing.T) {
+	origin := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	long := mapEvent{"start": origin, "end": origin.Add(4 * time.Hour)}
+	short := mapEvent{"start": origin.Add(time.Hour), "end": origin.Add(2 * time.Hour)}
+	e := NewEngine(orderedSchedule{long, short}, true)
+	e.Merge()
+	before := e.Snapshot()
+
+	provenances := e.Provenances()
+	if len(provenances) != 3 || !sameEvent(provenances[0].Original, long) || !provenances[0].Trimmed() ||
+		provenances[0].Fragments != 2 || provenances[1].Trimmed() || !sameEvent(provenances[2].Original, long) {
+		t.Fatalf("expected the parts to be traced back to their raw event, got %+v", provenances)
+	}
+	if len(e.Conflicts) != 1 || !sameEvent(e.Conflicts[0].Loser, long) || !sameEvent(e.Conflicts[0].Winner, short) {
+		t.Fatalf("unexpected conflicts %+v", e.Conflicts)
+	}
+	simulation, err := e.Simulate(2, mapEvent{"start": origin.Add(3 * time.Hour), "end": origin.Add(5 * time.Hour)})
+	if err != nil || len(simulation.Losses) != 1 || !sameEvent(simulation.Losses[0].Event, long) {
+		t.Fatalf("unexpected simulation %+v, %v", simulation, err)
+	}
+	if report := e.DetectConflicts(); len(report.Overlaps) != 1 || !sameEvent(report.Overlaps[0].LessDesirable, long) {
+		t.Fatalf("unexpected conflict report %+v", report)
+	}
+
+	if !e.Remove(short) {
+		t.Fatalf("expected the event to be removed")
+	}
+	e.Merge()
+	changes := e.Snapshot().Diff(before)
+	if len(changes.Added) != 0 || len(changes.Resized) != 1 || len(changes.Removed) != 2 {
+		t.Fatalf("unexpected changes %+v", changes)
+	}
+}
+
+func TestMultiEngine_Merge_UncomparableEvents(t *testing.T) {
+	origin := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	meeting := meetingEvent{"start": origin.Add(time.Hour), "end": origin.Add(2 * time.Hour),
+		"participants": []string{"ann", "bob"}}
+	leave := meetingEvent{"start": origin, "end": origin.Add(4 * time.Hour), "participants": []string{"ann"}}
+	m := NewMultiEngine(orderedSchedule{meeting, leave}, true, DropEverywhere)
+	m.Merge()
+
+	if len(m.PartialConflicts) != 1 || !m.PartialConflicts[0].Dropped || !sameEvent(m.PartialConflicts[0].Event, meeting) {
+		t.Fatalf("expected the meeting to be dropped, got %+v", m.PartialConflicts)
+	}
+	if timeline := m.Timeline("bob"); len(timeline) != 0 {
+		t.Fatalf("expected the meeting to be dropped from every timeline, got %v", spans(timeline))
+	}
+}
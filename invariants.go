@@ -0,0 +1,37 @@
+package scheduleMerge
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvariantViolated is returned by CheckInvariants if a merged schedule is not sorted or has overlapping events.
+var ErrInvariantViolated = errors.New("scheduleMerge: merged schedule violates an invariant")
+
+// CheckInvariants checks the invariants every merged schedule satisfies, e.g. in tests of code that creates or changes
+// merged schedules: every event starts before it ends, and every event starts at or after the end of the event before
+// it, so the events are sorted by time and never overlap. It returns an error wrapping ErrInvariantViolated that
+// names the first offending event, or nil.
+func CheckInvariants(merged []Event) error {
+	for i, event := range merged {
+		start, end := startOf(event), endOf(event)
+		if !start.Before(end) {
+			return fmt.Errorf("%w: event %d ends at %v, not after its start at %v", ErrInvariantViolated, i,
+				exposedBound(end), exposedBound(start))
+		}
+		if i == 0 {
+			continue
+		}
+
+		previous := merged[i-1]
+		if start.Before(startOf(previous)) {
+			return fmt.Errorf("%w: event %d starts at %v, before event %d at %v", ErrInvariantViolated, i,
+				exposedBound(start), i-1, exposedBound(startOf(previous)))
+		}
+		if start.Before(endOf(previous)) {
+			return fmt.Errorf("%w: event %d starts at %v, before event %d ends at %v", ErrInvariantViolated, i,
+				exposedBound(start), i-1, exposedBound(endOf(previous)))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package scheduleMerge
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCheckInvariants(t *testing.T) {
+	tests := []struct {
+		name   string
+		merged schedule
+		valid  bool
+	}{
+		{
+			name:   "empty",
+			merged: schedule{},
+			valid:  true,
+		},
+		{
+			name:   "sorted",
+			merged: schedule{{StartTime: at(0), EndTime: at(1)}, {StartTime: at(1), EndTime: at(2)}, {StartTime: at(3), EndTime: at(4)}},
+			valid:  true,
+		},
+		{
+			name:   "empty event",
+			merged: schedule{{StartTime: at(0), EndTime: at(0)}},
+		},
+		{
+			name:   "unsorted",
+			merged: schedule{{StartTime: at(2), EndTime: at(3)}, {StartTime: at(0), EndTime: at(1)}},
+		},
+		{
+			name:   "overlap",
+			merged: schedule{{StartTime: at(0), EndTime: at(2)}, {StartTime: at(1), EndTime: at(3)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckInvariants(tt.merged.GetEvents())
+			if tt.valid && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvariantViolated) {
+				t.Fatalf("expected ErrInvariantViolated, got %v", err)
+			}
+		})
+	}
+}
+
+// checkMergeProperties checks the merged schedule of the engine against its raw schedule, which has to hold events
+// with the PolicyDefault only:
+//   - the merged schedule satisfies CheckInvariants, i.e. it is sorted and free of overlaps;
+//   - every instant of the merged schedule is covered by (a part of) the most desirable raw event covering it;
+//   - if the overlaps are trimmed, every instant covered by a raw event is covered by the merged schedule;
+//   - if the overlaps are not trimmed, every merged event is a raw event.
+func checkMergeProperties(t *testing.T, e *Engine) {
+	t.Helper()
+
+	if err := CheckInvariants(e.MergedSchedule); err != nil {
+		t.Fatal(err)
+	}
+
+	raw := map[Event]bool{}
+	var bounds []time.Time
+	for _, rawEvent := range e.RawSchedule {
+		raw[rawEvent] = true
+		bounds = append(bounds, startOf(rawEvent), endOf(rawEvent))
+	}
+	sort.Slice(bounds, func(i, j int) bool {
+		return bounds[i].Before(bounds[j])
+	})
+	if !e.TrimOverlaps {
+		for _, mergedEvent := range e.MergedSchedule {
+			if !raw[mergedEvent] {
+				t.Fatalf("merged event %v is not a raw event", mergedEvent)
+			}
+		}
+	}
+
+	// Which event covers an instant only changes at the bounds of the raw events.
+	merged := 0
+	for _, instant := range bounds {
+		var mostDesirable Event
+		for _, rawEvent := range e.RawSchedule {
+			if !instant.Before(startOf(rawEvent)) && instant.Before(endOf(rawEvent)) {
+				mostDesirable = rawEvent
+			}
+		}
+
+		for merged < len(e.MergedSchedule) && !instant.Before(endOf(e.MergedSchedule[merged])) {
+			merged++
+		}
+		var covering Event
+		if merged < len(e.MergedSchedule) && !instant.Before(startOf(e.MergedSchedule[merged])) {
+			covering = e.MergedSchedule[merged]
+		}
+
+		switch {
+		case covering != nil && e.original(covering) != mostDesirable:
+			t.Fatalf("%v is covered by %v rather than by the most desirable raw event %v", instant, covering, mostDesirable)
+		case covering == nil && mostDesirable != nil && e.TrimOverlaps:
+			t.Fatalf("%v is not covered, although it is covered by the raw event %v", instant, mostDesirable)
+		}
+	}
+}
+
+func TestEngine_Merge_Properties(t *testing.T) {
+	for seed := int64(1); seed <= 200; seed++ {
+		for _, trimOverlaps := range []bool{true, false} {
+			raw := randomSchedule(seed, int(seed%40)+1, 48)
+			e := NewEngine(raw, trimOverlaps)
+			e.Merge()
+			checkMergeProperties(t, e)
+		}
+	}
+}
+
+// fuzzSchedule decodes a schedule from the fuzzer's data: every three bytes are the start (in minutes), the length (in
+// minutes, at least one) and the desirability of an event.
+func fuzzSchedule(data []byte) schedule {
+	origin := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var s schedule
+	for i := 0; i+2 < len(data); i += 3 {
+		start := origin.Add(time.Duration(data[i]) * time.Minute)
+		s = append(s, &event{
+			StartTime: start,
+			EndTime:   start.Add(time.Duration(data[i+1]%64+1) * time.Minute),
+			CreatedAt: origin.Add(time.Duration(data[i+2]) * time.Second),
+			ID:        len(s) + 1,
+		})
+	}
+	return s
+}
+
+func FuzzEngine_Merge(f *testing.F) {
+	f.Add([]byte{0, 10, 1, 5, 10, 2}, true)
+	f.Add([]byte{0, 60, 2, 10, 5, 1, 20, 5, 3, 0, 60, 2}, false)
+	f.Add([]byte{30, 3, 9, 0, 63, 1, 30, 3, 0, 31, 1, 5}, true)
+
+	f.Fuzz(func(t *testing.T, data []byte, trimOverlaps bool) {
+		e := NewEngine(fuzzSchedule(data), trimOverlaps)
+		e.Merge()
+		checkMergeProperties(t, e)
+	})
+}
+
+func FuzzEngine_MergeParallel(f *testing.F) {
+	f.Add(int64(1), uint16(0), uint8(10), true)
+	f.Add(int64(2), uint16(100), uint8(100), false)
+	f.Add(int64(3), uint16(300), uint8(255), true)
+
+	f.Fuzz(func(t *testing.T, seed int64, size uint16, hours uint8, trimOverlaps bool) {
+		// MergeParallel falls back to Merge unless every worker gets enough raw events.
+		const workers = 3
+		n := workers*minEventsPerWindow + int(size)%(4*minEventsPerWindow)
+		e := NewEngine(randomSchedule(seed, n, int(hours)+1), trimOverlaps)
+		e.MergeParallel(workers)
+		checkMergeProperties(t, e)
+
+		expected := NewEngine(randomSchedule(seed, n, int(hours)+1), trimOverlaps)
+		expected.Merge()
+		if diff := cmp.Diff(mergedEvents(expected.MergedSchedule), mergedEvents(e.MergedSchedule)); diff != "" {
+			t.Fatalf("unexpected merged schedule:\n%s", diff)
+		}
+	})
+}
@@ -0,0 +1,377 @@
+package scheduleMerge
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrInvalidJournal is returned when a journal cannot be decoded or replayed.
+var ErrInvalidJournal = errors.New("scheduleMerge: invalid journal")
+
+// OpKind is the kind of change an Op records.
+type OpKind int
+
+const (
+	// OpInsert records Engine.Insert (and Engine.Add).
+	OpInsert OpKind = iota + 1
+	// OpRemove records Engine.Remove.
+	OpRemove
+	// OpSetTrimOverlaps records Engine.SetTrimOverlaps.
+	OpSetTrimOverlaps
+	// OpSetLocation records Engine.SetLocation.
+	OpSetLocation
+	// OpUndo records Engine.Undo.
+	OpUndo
+	// OpRedo records Engine.Redo.
+	OpRedo
+	// OpReset records Engine.Reset. It empties the raw schedule; the events of the new raw schedule follow as OpInsert.
+	OpReset
+	// OpSetGranularity records the Granularity of the engine when the Journal was started.
+	OpSetGranularity
+	// OpSetMinFragment records the MinFragment of the engine when the Journal was started.
+	OpSetMinFragment
+)
+
+// String returns the name of the OpKind, which is also its name in an encoded journal.
+func (k OpKind) String() string {
+	switch k {
+	case OpInsert:
+		return "insert"
+	case OpRemove:
+		return "remove"
+	case OpSetTrimOverlaps:
+		return "set_trim_overlaps"
+	case OpSetLocation:
+		return "set_location"
+	case OpUndo:
+		return "undo"
+	case OpRedo:
+		return "redo"
+	case OpReset:
+		return "reset"
+	case OpSetGranularity:
+		return "set_granularity"
+	case OpSetMinFragment:
+		return "set_min_fragment"
+	default:
+		return "unknown"
+	}
+}
+
+// Op is a single change of an Engine recorded in a Journal.
+type Op struct {
+	// Kind is the kind of the change.
+	Kind OpKind
+	// At is the time the change was made.
+	At time.Time
+	// Rank is the position of the inserted (OpInsert) or removed (OpRemove) event in the raw schedule.
+	Rank int
+	// Event is a clone of the inserted event (OpInsert), taken when it was inserted, so that changing the event
+	// afterwards does not change the Journal.
+	Event Event
+	// TrimOverlaps is the new setting (OpSetTrimOverlaps).
+	TrimOverlaps bool
+	// Location is the new location (OpSetLocation). Nil stands for UTC.
+	Location *time.Location
+	// Duration is the new setting (OpSetGranularity and OpSetMinFragment).
+	Duration time.Duration
+}
+
+// Journal is an append-only record of the changes of an Engine. Replaying the first changes of a Journal rebuilds the
+// merged schedule the engine had after them, e.g. to find out who was double-booked last Tuesday.
+//
+// Only the changes made through the methods of the engine are recorded, together with the settings the engine had
+// when the Journal was started. Changing the fields of the engine directly (e.g. Granularity or Constraints) is not
+// recorded, and neither are CloneEvent, OnProgress and the Observers, which do not affect the merged schedule.
+//
+// A Journal is not safe for concurrent use. It must not be read while the engine recording it is being changed.
+type Journal struct {
+	// Ops holds the recorded changes in the order they were made.
+	Ops []Op
+	// Clock returns the time of a change. Defaults to time.Now.
+	Clock func() time.Time
+	// Constraints are the Constraints of the engine when the Journal was started (including the padding of New),
+	// which Replay applies to the replayed engine. They are not encoded, so they have to be set again on a decoded
+	// Journal.
+	Constraints []Constraint
+}
+
+// StartJournal starts recording the changes of the engine and returns the Journal they are recorded in. The Journal
+// starts with the current settings and raw schedule of the engine, so that it can be replayed on its own. Calling
+// StartJournal again starts a new Journal.
+//
+// StartJournal forgets the changes Undo and Redo could revert, as the Journal could not replay reverting them.
+func (e *Engine) StartJournal() *Journal {
+	e.undo, e.redo = nil, nil
+	e.journal = &Journal{Constraints: e.Constraints}
+	e.record(Op{Kind: OpSetTrimOverlaps, TrimOverlaps: e.TrimOverlaps})
+	e.record(Op{Kind: OpSetLocation, Location: e.Location})
+	e.record(Op{Kind: OpSetGranularity, Duration: e.Granularity})
+	e.record(Op{Kind: OpSetMinFragment, Duration: e.MinFragment})
+	for rank, rawEvent := range e.RawSchedule {
+		e.record(Op{Kind: OpInsert, Rank: rank, Event: rawEvent})
+	}
+	return e.journal
+}
+
+// record appends the change to the journal of the engine, if there is one.
+func (e *Engine) record(op Op) {
+	if e.journal == nil {
+		return
+	}
+
+	if e.journal.Clock != nil {
+		op.At = e.journal.Clock()
+	} else {
+		op.At = time.Now()
+	}
+	if op.Event != nil {
+		op.Event = op.Event.Clone()
+	}
+	e.journal.Ops = append(e.journal.Ops, op)
+}
+
+// Replay applies the first n changes of the journal to a new Engine and merges it. Replaying the same changes always
+// yields the same merged schedule. The new Engine merges clones of the recorded events, so that replaying does not
+// change the Journal. See Journal for what is not replayed.
+//
+// Undo and Redo are replayed by leaving out the changes they revert, so replaying takes linear time however many
+// changes are undone.
+func (j *Journal) Replay(n int) (*Engine, error) {
+	if n < 0 || n > len(j.Ops) {
+		return nil, fmt.Errorf("%w: cannot replay %d of %d changes", ErrInvalidJournal, n, len(j.Ops))
+	}
+
+	changes, err := j.effectiveChanges(n)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Engine{MergedSchedule: []Event{}, Constraints: j.Constraints}
+	for _, c := range changes {
+		switch op := c.op; op.Kind {
+		case OpInsert:
+			// The rank has been checked by effectiveChanges.
+			_ = e.Insert(op.Rank, op.Event.Clone())
+		case OpRemove:
+			e.removeAt(op.Rank)
+		case OpSetTrimOverlaps:
+			e.SetTrimOverlaps(op.TrimOverlaps)
+		case OpSetLocation:
+			e.SetLocation(op.Location)
+		case OpReset:
+			e.reset()
+		}
+	}
+	// Granularity and MinFragment cannot be undone, so the last of them is in effect.
+	for _, op := range j.Ops[:n] {
+		switch op.Kind {
+		case OpSetGranularity:
+			e.Granularity = op.Duration
+		case OpSetMinFragment:
+			e.MinFragment = op.Duration
+		}
+	}
+
+	e.Merge()
+	return e, nil
+}
+
+// journalChange is a change of the raw schedule or of the settings of an Engine that Replay applies, with the length
+// of the raw schedule after it.
+type journalChange struct {
+	op     Op
+	length int
+}
+
+// effectiveChanges returns the changes among the first n changes of the journal that were not reverted by Undo, in
+// the order they were made. Undo and Redo only move changes between the changes that are in effect and the ones that
+// were undone, so the state of the engine is never saved.
+func (j *Journal) effectiveChanges(n int) ([]journalChange, error) {
+	var (
+		changes, undone []journalChange
+		// floor is the number of changes Undo cannot revert, as Reset forgets them.
+		floor        int
+		lengthBefore = func() int {
+			if len(changes) == 0 {
+				return 0
+			}
+			return changes[len(changes)-1].length
+		}
+	)
+	for i, op := range j.Ops[:n] {
+		length := lengthBefore()
+		switch op.Kind {
+		case OpInsert:
+			if op.Rank < 0 || op.Rank > length {
+				return nil, fmt.Errorf("%w: change %d: %w: %d is not in [0, %d]", ErrInvalidJournal, i,
+					ErrInvalidRank, op.Rank, length)
+			}
+			length++
+		case OpRemove:
+			if op.Rank < 0 || op.Rank >= length {
+				return nil, fmt.Errorf("%w: change %d: %w: %d is not in [0, %d)", ErrInvalidJournal, i,
+					ErrInvalidRank, op.Rank, length)
+			}
+			length--
+		case OpSetTrimOverlaps, OpSetLocation:
+		case OpReset:
+			length = 0
+		case OpSetGranularity, OpSetMinFragment:
+			continue
+		case OpUndo:
+			if len(changes) == floor {
+				return nil, fmt.Errorf("%w: change %d undoes nothing", ErrInvalidJournal, i)
+			}
+			undone = append(undone, changes[len(changes)-1])
+			changes = changes[:len(changes)-1]
+			continue
+		case OpRedo:
+			if len(undone) == 0 {
+				return nil, fmt.Errorf("%w: change %d redoes nothing", ErrInvalidJournal, i)
+			}
+			changes = append(changes, undone[len(undone)-1])
+			undone = undone[:len(undone)-1]
+			continue
+		default:
+			return nil, fmt.Errorf("%w: change %d has unknown kind %d", ErrInvalidJournal, i, op.Kind)
+		}
+
+		// Any other change forgets the undone changes.
+		changes = append(changes, journalChange{op: op, length: length})
+		undone = undone[:0]
+		if op.Kind == OpReset {
+			floor = len(changes)
+		}
+	}
+	return changes, nil
+}
+
+// ReplayUntil replays the changes made before t. See Replay.
+func (j *Journal) ReplayUntil(t time.Time) (*Engine, error) {
+	n := 0
+	for n < len(j.Ops) && j.Ops[n].At.Before(t) {
+		n++
+	}
+	return j.Replay(n)
+}
+
+// encodedOp is the JSON representation of an Op: a single line of an encoded journal.
+type encodedOp struct {
+	Op           string          `json:"op"`
+	At           time.Time       `json:"at"`
+	Rank         *int            `json:"rank,omitempty"`
+	Event        json.RawMessage `json:"event,omitempty"`
+	TrimOverlaps *bool           `json:"trimOverlaps,omitempty"`
+	Location     *string         `json:"location,omitempty"`
+	Duration     *time.Duration  `json:"duration,omitempty"`
+}
+
+// Encode writes the journal to w as JSON lines, one change per line, e.g. to keep it in a local file. The inserted
+// events are encoded by encode, which has to produce valid JSON. Locations are encoded by name, so Encode fails on a
+// location that time.LoadLocation cannot load by its name (e.g. one made by time.FixedZone).
+func (j *Journal) Encode(w io.Writer, encode func(Event) ([]byte, error)) error {
+	enc := json.NewEncoder(w)
+	for i, op := range j.Ops {
+		encoded := encodedOp{Op: op.Kind.String(), At: op.At}
+		switch op.Kind {
+		case OpInsert:
+			event, err := encode(op.Event)
+			if err != nil {
+				return fmt.Errorf("scheduleMerge: encoding the event of change %d: %w", i, err)
+			}
+			encoded.Rank, encoded.Event = &op.Rank, event
+		case OpRemove:
+			encoded.Rank = &op.Rank
+		case OpSetTrimOverlaps:
+			encoded.TrimOverlaps = &op.TrimOverlaps
+		case OpSetLocation:
+			location, err := locationName(op.Location)
+			if err != nil {
+				return fmt.Errorf("scheduleMerge: encoding the location of change %d: %w", i, err)
+			}
+			encoded.Location = &location
+		case OpSetGranularity, OpSetMinFragment:
+			encoded.Duration = &op.Duration
+		}
+
+		if err := enc.Encode(encoded); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// locationName returns the name DecodeJournal loads the location by. Locations that cannot be loaded by their name,
+// such as time.FixedZone, cannot be encoded.
+func locationName(loc *time.Location) (string, error) {
+	if loc == nil {
+		return "", nil
+	}
+	name := loc.String()
+	if name == "" {
+		return "", errors.New("location without a name")
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// DecodeJournal reads a journal written by Journal.Encode. The inserted events are decoded by decode.
+func DecodeJournal(r io.Reader, decode func([]byte) (Event, error)) (*Journal, error) {
+	kinds := map[string]OpKind{}
+	for _, kind := range []OpKind{OpInsert, OpRemove, OpSetTrimOverlaps, OpSetLocation, OpUndo, OpRedo, OpReset,
+		OpSetGranularity, OpSetMinFragment} {
+		kinds[kind.String()] = kind
+	}
+
+	j := &Journal{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var encoded encodedOp
+		if err := json.Unmarshal(scanner.Bytes(), &encoded); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidJournal, line, err)
+		}
+		op := Op{Kind: kinds[encoded.Op], At: encoded.At}
+		if encoded.Rank != nil {
+			op.Rank = *encoded.Rank
+		}
+
+		var err error
+		switch op.Kind {
+		case OpInsert:
+			op.Event, err = decode(encoded.Event)
+		case OpRemove, OpUndo, OpRedo, OpReset:
+		case OpSetTrimOverlaps:
+			op.TrimOverlaps = encoded.TrimOverlaps != nil && *encoded.TrimOverlaps
+		case OpSetLocation:
+			if encoded.Location != nil && *encoded.Location != "" {
+				op.Location, err = time.LoadLocation(*encoded.Location)
+			}
+		case OpSetGranularity, OpSetMinFragment:
+			if encoded.Duration != nil {
+				op.Duration = *encoded.Duration
+			}
+		default:
+			err = fmt.Errorf("unknown change %q", encoded.Op)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidJournal, line, err)
+		}
+		j.Ops = append(j.Ops, op)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
@@ -0,0 +1,283 @@
+package scheduleMerge
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestJournal_Replay(t *testing.T) {
+	e := NewEngine(schedule{
+		{StartTime: at(0), EndTime: at(4), CreatedAt: at(0), ID: 1},
+	}, true)
+	journal := e.StartJournal()
+	// The changes are made at 2100-01-01 00:00, 01:00, 02:00, ...
+	now := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+	journal.Clock = func() time.Time {
+		defer func() { now = now.Add(time.Hour) }()
+		return now
+	}
+
+	var states [][]event
+	snapshot := func() {
+		e.Merge()
+		states = append(states, mergedEvents(e.MergedSchedule))
+	}
+	snapshot()
+	second := &event{StartTime: at(2), EndTime: at(6), CreatedAt: at(1), ID: 2}
+	e.Add(second)
+	snapshot()
+	if err := e.Insert(0, &event{StartTime: at(5), EndTime: at(8), CreatedAt: at(-1), ID: 3}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	snapshot()
+	e.SetTrimOverlaps(false)
+	snapshot()
+	e.Remove(second)
+	snapshot()
+	e.SetLocation(time.UTC)
+	snapshot()
+
+	// The journal starts with the settings and the raw schedule of the engine.
+	base := 5
+	if len(journal.Ops) != base+5 {
+		t.Fatalf("expected %d changes, got %d", base+5, len(journal.Ops))
+	}
+
+	var encoded bytes.Buffer
+	err := journal.Encode(&encoded, func(ev Event) ([]byte, error) {
+		return json.Marshal(ev)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lines := strings.Count(encoded.String(), "\n"); lines != len(journal.Ops) {
+		t.Fatalf("expected a line per change, got %d lines", lines)
+	}
+	decoded, err := DecodeJournal(&encoded, func(data []byte) (Event, error) {
+		ev := &event{}
+		return ev, json.Unmarshal(data, ev)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for name, j := range map[string]*Journal{"recorded": journal, "decoded": decoded} {
+		t.Run(name, func(t *testing.T) {
+			for i, expected := range states {
+				replayed, err := j.Replay(base + i)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if diff := cmp.Diff(expected, mergedEvents(replayed.MergedSchedule)); diff != "" {
+					t.Fatalf("unexpected merged schedule after %d changes:\n%s", base+i, diff)
+				}
+			}
+
+			// The removal was made at 03:00.
+			replayed, err := j.ReplayUntil(time.Date(2100, 1, 1, 2, 30, 0, 0, time.UTC))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(states[3], mergedEvents(replayed.MergedSchedule)); diff != "" {
+				t.Fatalf("unexpected merged schedule:\n%s", diff)
+			}
+		})
+	}
+
+	// Replaying does not change the recorded events.
+	if second.StartTime != at(2) || second.EndTime != at(6) {
+		t.Fatalf("expected the recorded event to be unchanged, got %v", second)
+	}
+}
+
+func TestJournal_Invalid(t *testing.T) {
+	decode := func(data []byte) (Event, error) {
+		ev := &event{}
+		return ev, json.Unmarshal(data, ev)
+	}
+
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{name: "malformed line", encoded: "{\n"},
+		{name: "unknown change", encoded: `{"op":"rename","at":"2030-01-01T00:00:00Z"}` + "\n"},
+		{name: "unknown location", encoded: `{"op":"set_location","at":"2030-01-01T00:00:00Z","location":"Mars/Olympus"}` + "\n"},
+		{name: "malformed event", encoded: `{"op":"insert","at":"2030-01-01T00:00:00Z","rank":0,"event":[]}` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeJournal(strings.NewReader(tt.encoded), decode); !errors.Is(err, ErrInvalidJournal) {
+				t.Fatalf("expected %v, got %v", ErrInvalidJournal, err)
+			}
+		})
+	}
+
+	t.Run("removing a missing event", func(t *testing.T) {
+		j, err := DecodeJournal(strings.NewReader(`{"op":"remove","at":"2030-01-01T00:00:00Z","rank":0}`+"\n"), decode)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := j.Replay(1); !errors.Is(err, ErrInvalidJournal) || !errors.Is(err, ErrInvalidRank) {
+			t.Fatalf("expected %v and %v, got %v", ErrInvalidJournal, ErrInvalidRank, err)
+		}
+	})
+
+	t.Run("replaying too many changes", func(t *testing.T) {
+		if _, err := (&Journal{}).Replay(1); !errors.Is(err, ErrInvalidJournal) {
+			t.Fatalf("expected %v, got %v", ErrInvalidJournal, err)
+		}
+	})
+}
+
+func TestJournal_Replay_Settings(t *testing.T) {
+	e, err := New(randomSchedule(1, 100, 100), WithGranularity(15*time.Minute), WithMinFragment(30*time.Minute),
+		WithPadding(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	journal := e.StartJournal()
+	e.Merge()
+
+	var encoded bytes.Buffer
+	err = journal.Encode(&encoded, func(ev Event) ([]byte, error) {
+		return json.Marshal(ev)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	decoded, err := DecodeJournal(&encoded, func(data []byte) (Event, error) {
+		ev := &event{}
+		return ev, json.Unmarshal(data, ev)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The constraints are not encoded.
+	decoded.Constraints = e.Constraints
+
+	for name, j := range map[string]*Journal{"recorded": journal, "decoded": decoded} {
+		t.Run(name, func(t *testing.T) {
+			replayed, err := j.Replay(len(j.Ops))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if replayed.Granularity != e.Granularity || replayed.MinFragment != e.MinFragment {
+				t.Fatalf("expected granularity %v and minimum fragment %v, got %v and %v", e.Granularity,
+					e.MinFragment, replayed.Granularity, replayed.MinFragment)
+			}
+			if diff := cmp.Diff(mergedEvents(e.MergedSchedule), mergedEvents(replayed.MergedSchedule)); diff != "" {
+				t.Fatalf("unexpected merged schedule:\n%s", diff)
+			}
+			if len(replayed.Violations) != len(e.Violations) {
+				t.Fatalf("expected %d violations, got %d", len(e.Violations), len(replayed.Violations))
+			}
+		})
+	}
+}
+
+func TestJournal_Encode_UnnamedLocation(t *testing.T) {
+	e := NewEngine(schedule{}, true)
+	journal := e.StartJournal()
+	e.SetLocation(time.FixedZone("", 2*60*60))
+
+	err := journal.Encode(io.Discard, func(ev Event) ([]byte, error) {
+		return json.Marshal(ev)
+	})
+	if err == nil {
+		t.Fatalf("expected an error encoding a location without a name")
+	}
+}
+
+func TestJournal_ReplayUndo_Random(t *testing.T) {
+	rawSchedule := randomSchedule(1, 200, 50)
+	e := NewEngine(schedule{}, true)
+	e.UndoLimit = 5
+	journal := e.StartJournal()
+
+	r := rand.New(rand.NewSource(1))
+	for i, ev := range rawSchedule {
+		switch n := r.Intn(10); {
+		case n < 2:
+			e.Undo()
+		case n < 3:
+			e.Redo()
+		case n < 4 && len(e.RawSchedule) > 0:
+			e.Remove(e.RawSchedule[r.Intn(len(e.RawSchedule))])
+		case n < 5:
+			e.SetTrimOverlaps(!e.TrimOverlaps)
+		default:
+			if err := e.Insert(r.Intn(len(e.RawSchedule)+1), ev); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		e.Merge()
+
+		replayed, err := journal.Replay(len(journal.Ops))
+		if err != nil {
+			t.Fatalf("change %d: unexpected error: %v", i, err)
+		}
+		if diff := cmp.Diff(mergedEvents(e.MergedSchedule), mergedEvents(replayed.MergedSchedule)); diff != "" {
+			t.Fatalf("change %d: unexpected merged schedule:\n%s", i, diff)
+		}
+	}
+}
+
+func TestJournal_Replay_Memory(t *testing.T) {
+	const n = 8000
+	rawSchedule := randomSchedule(1, n, 10*n)
+	rawSchedule.SortByDesirability()
+	e := NewEngine(schedule{}, true)
+	e.UndoLimit = 1
+	journal := e.StartJournal()
+	for _, ev := range rawSchedule {
+		e.Add(ev)
+	}
+	e.Undo()
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if _, err := journal.Replay(len(journal.Ops)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	runtime.ReadMemStats(&after)
+
+	// Saving the raw schedule for every change would allocate hundreds of megabytes.
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 32<<20 {
+		t.Fatalf("replaying %d changes allocated %d bytes", n, allocated)
+	}
+}
+
+func TestJournal_Replay_ChangedEvent(t *testing.T) {
+	e := NewEngine(schedule{
+		{StartTime: at(0), EndTime: at(4), ID: 1},
+	}, true)
+	journal := e.StartJournal()
+	added := &event{StartTime: at(2), EndTime: at(6), ID: 2}
+	e.Add(added)
+	e.Merge()
+	expected := mergedEvents(e.MergedSchedule)
+	n := len(journal.Ops)
+
+	// The caller reuses the event for another change.
+	added.StartTime = at(8)
+	added.EndTime = at(9)
+	e.Add(&event{StartTime: at(10), EndTime: at(11), ID: 3})
+
+	replayed, err := journal.Replay(n)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(expected, mergedEvents(replayed.MergedSchedule)); diff != "" {
+		t.Fatalf("expected the journal to keep the event as it was added:\n%s", diff)
+	}
+}
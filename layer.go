@@ -0,0 +1,65 @@
+package scheduleMerge
+
+import (
+	"sort"
+)
+
+// Layer is a source of raw events, e.g. one of the calendars of a person, with its priority among the other sources.
+type Layer struct {
+	// Source names the source of the events, e.g. "hr" or "roster".
+	Source string
+	// Rank orders the layers: every event of a layer is more desirable than all the events of the layers with a lower
+	// Rank. Layers with the same Rank are ordered by their position, the later layer being more desirable.
+	Rank int
+	// Schedule holds the events of the layer. Within the layer, the events are ordered by its SortByDesirability.
+	Schedule Schedule
+}
+
+// layeredSchedule is the Schedule of several layers, ordered by their ranks.
+type layeredSchedule struct {
+	layers []Layer
+	events []Event
+}
+
+// SortByDesirability sorts the layers by their ranks and every layer by its own desirability.
+func (s *layeredSchedule) SortByDesirability() {
+	sort.SliceStable(s.layers, func(i, j int) bool {
+		return s.layers[i].Rank < s.layers[j].Rank
+	})
+	s.events = s.events[:0]
+	for _, layer := range s.layers {
+		layer.Schedule.SortByDesirability()
+		s.events = append(s.events, layer.Schedule.GetEvents()...)
+	}
+}
+
+// GetEvents returns the events of all the layers.
+func (s *layeredSchedule) GetEvents() []Event {
+	return s.events
+}
+
+// NewLayeredEngine creates an Engine for the events of several sources. The raw schedule holds the events of the
+// layers ordered by their Rank, and within every layer by the SortByDesirability of its Schedule. Pinned events (see
+// PolicyPinned) are still more desirable than all the other events, whatever their layer.
+//
+// The engine remembers the Source of every raw event (see Engine.Source). Events added later, e.g. by Add or Insert,
+// have no Source.
+func NewLayeredEngine(layers []Layer, trimOverlaps bool) *Engine {
+	schedule := &layeredSchedule{layers: append([]Layer(nil), layers...)}
+	e := NewEngine(schedule, trimOverlaps)
+
+	e.sources = eventMap[string]{}
+	for _, layer := range schedule.layers {
+		for _, event := range layer.Schedule.GetEvents() {
+			e.sources.set(event, layer.Source)
+		}
+	}
+	return e
+}
+
+// Source returns the Source of the Layer the event comes from. The event can be a raw event or an event of the merged
+// schedule. It reports false if the event does not come from a Layer.
+func (e *Engine) Source(event Event) (string, bool) {
+	source, ok := e.sources.lookup(e.original(event))
+	return source, ok
+}
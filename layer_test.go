@@ -0,0 +1,85 @@
+package scheduleMerge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewLayeredEngine(t *testing.T) {
+	origin := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var (
+		leave   = &event{StartTime: at(0), EndTime: at(4), CreatedAt: at(0), ID: 1}
+		shift   = &event{StartTime: at(2), EndTime: at(10), CreatedAt: at(5), ID: 2}
+		swap    = &event{StartTime: at(8), EndTime: at(12), CreatedAt: at(6), ID: 3}
+		meeting = &event{StartTime: at(6), EndTime: at(9), CreatedAt: at(9), ID: 4}
+	)
+	e := NewLayeredEngine([]Layer{
+		{Source: "hr", Rank: 2, Schedule: schedule{leave}},
+		{Source: "roster", Rank: 0, Schedule: schedule{swap, shift}},
+		{Source: "meetings", Rank: 1, Schedule: schedule{meeting}},
+	}, true)
+
+	if diff := cmp.Diff([]Event{shift, swap, meeting, leave}, e.RawSchedule); diff != "" {
+		t.Fatalf("unexpected raw schedule:\n%s", diff)
+	}
+	e.Merge()
+
+	// source describes an event of the merged schedule by its ID, bounds and source.
+	type source struct {
+		ID         int
+		Start, End int
+		Source     string
+	}
+	var got []source
+	for _, mergedEvent := range e.MergedSchedule {
+		s, ok := e.Source(mergedEvent)
+		if !ok {
+			t.Fatalf("expected %v to have a source", mergedEvent)
+		}
+		ev := mergedEvent.(*event)
+		got = append(got, source{ID: ev.ID, Start: int(ev.StartTime.Sub(origin).Hours()), End: int(ev.EndTime.Sub(origin).Hours()), Source: s})
+	}
+	expected := []source{
+		{ID: 1, Start: 0, End: 4, Source: "hr"},
+		{ID: 2, Start: 4, End: 6, Source: "roster"},
+		{ID: 4, Start: 6, End: 9, Source: "meetings"},
+		{ID: 3, Start: 9, End: 12, Source: "roster"},
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Fatalf("unexpected merged schedule:\n%s", diff)
+	}
+
+	var sources []string
+	for _, p := range e.Snapshot().Provenances() {
+		sources = append(sources, p.Source)
+	}
+	if diff := cmp.Diff([]string{"hr", "roster", "meetings", "roster"}, sources); diff != "" {
+		t.Fatalf("unexpected provenance sources:\n%s", diff)
+	}
+
+	added := &event{StartTime: at(20), EndTime: at(21), ID: 5}
+	e.Add(added)
+	if _, ok := e.Source(added); ok {
+		t.Fatalf("expected an added event to have no source")
+	}
+}
+
+func TestNewLayeredEngine_SameRank(t *testing.T) {
+	origin := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var (
+		first  = &event{StartTime: origin, EndTime: origin.Add(time.Hour), CreatedAt: origin.Add(time.Hour), ID: 1}
+		second = &event{StartTime: origin, EndTime: origin.Add(time.Hour), CreatedAt: origin, ID: 2}
+	)
+	e := NewLayeredEngine([]Layer{
+		{Source: "first", Schedule: schedule{first}},
+		{Source: "second", Schedule: schedule{second}},
+	}, false)
+	e.Merge()
+
+	if len(e.MergedSchedule) != 1 || e.MergedSchedule[0] != second {
+		t.Fatalf("expected the event of the later layer to win, got %v", mergedEvents(e.MergedSchedule))
+	}
+}
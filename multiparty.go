@@ -0,0 +1,219 @@
+package scheduleMerge
+
+import (
+	"sort"
+	"time"
+)
+
+// ParticipantEvent is an optional interface for Event(s) that belong to the timelines of several participants, e.g.
+// a meeting with several attendees.
+type ParticipantEvent interface {
+	Event
+	// GetParticipants returns the participants whose timelines the Event belongs to.
+	GetParticipants() []string
+}
+
+// Consistency is how a MultiEngine treats an event shared by several timelines that loses an overlap on some of them.
+type Consistency int
+
+const (
+	// DropEverywhere removes a shared event that loses an overlap on any timeline from all its timelines, e.g. a
+	// meeting that cannot take place because one of its attendees is on leave.
+	DropEverywhere Consistency = iota
+	// KeepFlagged merges every timeline on its own, so a shared event might be kept on some timelines and trimmed or
+	// discarded on others. The shared event is reported as a PartialConflict.
+	KeepFlagged
+)
+
+// String returns the name of the Consistency.
+func (c Consistency) String() string {
+	switch c {
+	case DropEverywhere:
+		return "drop_everywhere"
+	case KeepFlagged:
+		return "keep_flagged"
+	default:
+		return "unknown"
+	}
+}
+
+// PartialConflict is a shared event that lost an overlap on some of its timelines.
+type PartialConflict struct {
+	// Event is the shared raw event.
+	Event Event
+	// Participants are the participants on whose timelines Event lost an overlap, in sorted order.
+	Participants []string
+	// Conflicts are the conflicts Event lost on those timelines.
+	Conflicts []Conflict
+	// Dropped indicates that Event was removed from all its timelines (see DropEverywhere).
+	Dropped bool
+}
+
+// MultiEngine merges the timelines of several participants, e.g. the people of a team. Every participant has a
+// timeline of its own, merged by an Engine; a ParticipantEvent belongs to the timelines of all its participants, and
+// any other event belongs to every timeline (e.g. a public holiday) and is merged into every timeline on its own. The
+// shared events, i.e. the ParticipantEvent(s) with several participants, are kept consistent across their timelines
+// according to the Consistency.
+type MultiEngine struct {
+	// The raw schedule passed to the engine via the NewMultiEngine constructor, sorted by desirability in ascending
+	// order. Pinned events (see PolicyPinned) come after all the other events.
+	RawSchedule []Event
+	// Indicates whether the engines of the timelines trim the overlaps between the events (see Engine.TrimOverlaps).
+	TrimOverlaps bool
+	// The time zone the dates of AllDayEvent(s) are resolved in. Defaults to UTC if nil.
+	Location *time.Location
+	// How shared events that lose an overlap on some of their timelines are treated.
+	Consistency Consistency
+	// The engines of the timelines by participant, created by Merge. The raw schedule of every engine holds the
+	// events of the participant, without the events dropped by DropEverywhere.
+	Timelines map[string]*Engine
+	// The shared events that lost an overlap on some of their timelines, in the order of the raw schedule.
+	PartialConflicts []PartialConflict
+
+	mergingFinished bool
+}
+
+// NewMultiEngine creates a MultiEngine for the rawSchedule.
+func NewMultiEngine(rawSchedule Schedule, trimOverlaps bool, consistency Consistency) *MultiEngine {
+	return &MultiEngine{
+		RawSchedule:  NewEngine(rawSchedule, trimOverlaps).RawSchedule,
+		TrimOverlaps: trimOverlaps,
+		Consistency:  consistency,
+	}
+}
+
+// Merge merges the timelines of all the participants. Calling Merge again after it has finished is a no-op.
+//
+// With DropEverywhere, the timelines of the participants of every dropped event are merged again, as dropping an event
+// gives its time back to the less desirable events of its timelines. Whether an event loses an overlap only depends
+// on the more desirable events, so the shared events are dropped from the most desirable to the least desirable one.
+func (m *MultiEngine) Merge() {
+	if m.mergingFinished {
+		return
+	}
+
+	m.PartialConflicts = nil
+	m.mergeTimelines()
+	for {
+		partialConflicts := m.partialConflicts()
+		if m.Consistency != DropEverywhere || len(partialConflicts) == 0 {
+			m.PartialConflicts = append(m.PartialConflicts, partialConflicts...)
+			break
+		}
+
+		mostDesirable := partialConflicts[len(partialConflicts)-1]
+		mostDesirable.Dropped = true
+		m.drop(mostDesirable.Event)
+		m.PartialConflicts = append(m.PartialConflicts, mostDesirable)
+	}
+
+	rank := rankByEvent(m.RawSchedule)
+	sort.Slice(m.PartialConflicts, func(i, j int) bool {
+		return rank.get(m.PartialConflicts[i].Event) < rank.get(m.PartialConflicts[j].Event)
+	})
+	m.mergingFinished = true
+}
+
+// Participants returns the participants of all the timelines, in sorted order.
+func (m *MultiEngine) Participants() []string {
+	seen := map[string]bool{}
+	var participants []string
+	for _, rawEvent := range m.RawSchedule {
+		if participantEvent, ok := rawEvent.(ParticipantEvent); ok {
+			for _, participant := range participantEvent.GetParticipants() {
+				if !seen[participant] {
+					seen[participant] = true
+					participants = append(participants, participant)
+				}
+			}
+		}
+	}
+	sort.Strings(participants)
+	return participants
+}
+
+// Timeline returns the merged schedule of the participant. It is nil until Merge has been called.
+func (m *MultiEngine) Timeline(participant string) []Event {
+	if timeline, ok := m.Timelines[participant]; ok {
+		return timeline.MergedSchedule
+	}
+	return nil
+}
+
+// mergeTimelines merges the timeline of every participant.
+func (m *MultiEngine) mergeTimelines() {
+	participants := m.Participants()
+	m.Timelines = make(map[string]*Engine, len(participants))
+	for _, participant := range participants {
+		m.Timelines[participant] = &Engine{
+			MergedSchedule: []Event{},
+			TrimOverlaps:   m.TrimOverlaps,
+			Location:       m.Location,
+		}
+	}
+
+	for _, rawEvent := range m.RawSchedule {
+		for _, participant := range participantsOf(rawEvent, participants) {
+			timeline := m.Timelines[participant]
+			timeline.RawSchedule = append(timeline.RawSchedule, rawEvent)
+		}
+	}
+	for _, participant := range participants {
+		m.Timelines[participant].Merge()
+	}
+}
+
+// drop removes the shared event from the timelines of its participants and merges them again. The other timelines
+// are left as they are.
+func (m *MultiEngine) drop(sharedEvent Event) {
+	for _, participant := range sharedEvent.(ParticipantEvent).GetParticipants() {
+		timeline := m.Timelines[participant]
+		timeline.Remove(sharedEvent)
+		timeline.Merge()
+	}
+}
+
+// partialConflicts returns the shared events that lost an overlap on the timelines, in the order of the raw
+// schedule.
+func (m *MultiEngine) partialConflicts() []PartialConflict {
+	partial := eventMap[*PartialConflict]{}
+	for _, participant := range m.Participants() {
+		for _, conflict := range m.Timelines[participant].Conflicts {
+			if !isShared(conflict.Loser) {
+				continue
+			}
+			p, ok := partial.lookup(conflict.Loser)
+			if !ok {
+				p = &PartialConflict{Event: conflict.Loser}
+				partial.set(conflict.Loser, p)
+			}
+			if n := len(p.Participants); n == 0 || p.Participants[n-1] != participant {
+				p.Participants = append(p.Participants, participant)
+			}
+			p.Conflicts = append(p.Conflicts, conflict)
+		}
+	}
+
+	var partialConflicts []PartialConflict
+	for _, rawEvent := range m.RawSchedule {
+		if p, ok := partial.lookup(rawEvent); ok {
+			partialConflicts = append(partialConflicts, *p)
+		}
+	}
+	return partialConflicts
+}
+
+// participantsOf returns the participants whose timelines the raw event belongs to: its own participants if it is a
+// ParticipantEvent, and all the participants otherwise.
+func participantsOf(rawEvent Event, participants []string) []string {
+	if participantEvent, ok := rawEvent.(ParticipantEvent); ok {
+		return participantEvent.GetParticipants()
+	}
+	return participants
+}
+
+// isShared reports whether the event is a ParticipantEvent with several participants.
+func isShared(event Event) bool {
+	participantEvent, ok := event.(ParticipantEvent)
+	return ok && len(participantEvent.GetParticipants()) > 1
+}
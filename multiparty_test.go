@@ -0,0 +1,185 @@
+package scheduleMerge
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type participantEvent struct {
+	event
+	Participants []string
+}
+
+func (e *participantEvent) GetParticipants() []string {
+	return e.Participants
+}
+
+func (e *participantEvent) Clone() Event {
+	clone := *e
+	return &clone
+}
+
+func TestMultiEngine_Merge(t *testing.T) {
+	origin := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	ev := func(id, start, end int, participants ...string) *participantEvent {
+		return &participantEvent{event: event{StartTime: at(start), EndTime: at(end), ID: id}, Participants: participants}
+	}
+	// span describes a merged event by its ID and bounds.
+	type span struct {
+		ID         int
+		Start, End int
+	}
+	// partialConflict describes a PartialConflict by the ID of its event.
+	type partialConflict struct {
+		ID           int
+		Participants []string
+		Dropped      bool
+	}
+
+	var (
+		standup = ev(1, 9, 10, "alice", "bob")
+		review  = ev(2, 10, 12, "alice", "bob")
+		focus   = ev(3, 9, 11, "bob")
+		leave   = ev(4, 11, 13, "alice")
+		// raw is sorted by desirability in ascending order.
+		raw = orderedSchedule{standup, review, focus, leave}
+	)
+
+	tests := []struct {
+		name        string
+		consistency Consistency
+		expected    map[string][]span
+		partial     []partialConflict
+	}{
+		{
+			name:        "drop everywhere",
+			consistency: DropEverywhere,
+			expected: map[string][]span{
+				"alice": {{4, 11, 13}},
+				"bob":   {{3, 9, 11}},
+			},
+			partial: []partialConflict{
+				{ID: 1, Participants: []string{"bob"}, Dropped: true},
+				{ID: 2, Participants: []string{"alice", "bob"}, Dropped: true},
+			},
+		},
+		{
+			name:        "keep flagged",
+			consistency: KeepFlagged,
+			expected: map[string][]span{
+				"alice": {{1, 9, 10}, {2, 10, 11}, {4, 11, 13}},
+				"bob":   {{3, 9, 11}, {2, 11, 12}},
+			},
+			partial: []partialConflict{
+				{ID: 1, Participants: []string{"bob"}},
+				{ID: 2, Participants: []string{"alice", "bob"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMultiEngine(raw, true, tt.consistency)
+			m.Merge()
+
+			if diff := cmp.Diff([]string{"alice", "bob"}, m.Participants()); diff != "" {
+				t.Fatalf("unexpected participants:\n%s", diff)
+			}
+			got := map[string][]span{}
+			for _, participant := range m.Participants() {
+				for _, mergedEvent := range m.Timeline(participant) {
+					pe := mergedEvent.(*participantEvent)
+					got[participant] = append(got[participant], span{ID: pe.ID, Start: int(pe.StartTime.Sub(origin).Hours()), End: int(pe.EndTime.Sub(origin).Hours())})
+				}
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("unexpected timelines:\n%s", diff)
+			}
+
+			var partial []partialConflict
+			for _, p := range m.PartialConflicts {
+				if len(p.Conflicts) == 0 {
+					t.Errorf("expected the conflicts of %v", p.Event)
+				}
+				partial = append(partial, partialConflict{ID: p.Event.(*participantEvent).ID, Participants: p.Participants, Dropped: p.Dropped})
+			}
+			if diff := cmp.Diff(tt.partial, partial); diff != "" {
+				t.Errorf("unexpected partial conflicts:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMultiEngine_Merge_DropGivesTimeBack(t *testing.T) {
+	var (
+		// workshop is dropped because of the leave of alice, so it no longer blocks the call of bob.
+		call     = &participantEvent{event: event{StartTime: at(9), EndTime: at(10), ID: 1}, Participants: []string{"bob", "carol"}}
+		workshop = &participantEvent{event: event{StartTime: at(9), EndTime: at(12), ID: 2}, Participants: []string{"alice", "bob"}}
+		leave    = &participantEvent{event: event{StartTime: at(8), EndTime: at(17), ID: 3}, Participants: []string{"alice"}}
+		holiday  = &event{StartTime: at(24), EndTime: at(48), ID: 4}
+	)
+	m := NewMultiEngine(orderedSchedule{holiday, call, workshop, leave}, false, DropEverywhere)
+	m.Merge()
+
+	if diff := cmp.Diff([]Event{call, holiday}, m.Timeline("bob"), cmp.AllowUnexported(participantEvent{})); diff != "" {
+		t.Fatalf("unexpected timeline of bob:\n%s", diff)
+	}
+	if diff := cmp.Diff([]Event{call, holiday}, m.Timeline("carol"), cmp.AllowUnexported(participantEvent{})); diff != "" {
+		t.Fatalf("unexpected timeline of carol:\n%s", diff)
+	}
+	if len(m.PartialConflicts) != 1 || m.PartialConflicts[0].Event != workshop {
+		t.Fatalf("expected only the workshop to be dropped, got %v", m.PartialConflicts)
+	}
+}
+
+func TestMultiEngine_Merge_DropEverywhere_Random(t *testing.T) {
+	names := []string{"alice", "bob", "carol", "dave", "erin"}
+	raw := randomSchedule(3, 120, 24*5)
+	raw.SortByDesirability()
+	events := make(orderedSchedule, len(raw))
+	for i, ev := range raw {
+		// Every event belongs to two or three of the participants.
+		participants := []string{names[i%len(names)], names[(i+1)%len(names)]}
+		if i%3 == 0 {
+			participants = append(participants, names[(i+3)%len(names)])
+		}
+		events[i] = &participantEvent{event: *ev, Participants: participants}
+	}
+
+	for _, trimOverlaps := range []bool{true, false} {
+		m := NewMultiEngine(events, trimOverlaps, DropEverywhere)
+		m.Merge()
+
+		if len(m.PartialConflicts) == 0 {
+			t.Fatalf("trimOverlaps %v: expected some events to be dropped", trimOverlaps)
+		}
+		dropped := eventMap[bool]{}
+		for _, p := range m.PartialConflicts {
+			if !p.Dropped {
+				t.Fatalf("trimOverlaps %v: expected %v to be dropped", trimOverlaps, p.Event)
+			}
+			dropped.set(p.Event, true)
+		}
+		// Every timeline is the one a new engine merges from the events that were not dropped.
+		for _, participant := range m.Participants() {
+			var rawSchedule orderedSchedule
+			for _, ev := range events {
+				if !dropped.get(ev) && slices.Contains(ev.(*participantEvent).Participants, participant) {
+					rawSchedule = append(rawSchedule, ev)
+				}
+			}
+			expected := NewEngine(rawSchedule, trimOverlaps)
+			expected.Merge()
+			if diff := cmp.Diff(expected.MergedSchedule, m.Timeline(participant), cmp.AllowUnexported(participantEvent{})); diff != "" {
+				t.Fatalf("trimOverlaps %v: unexpected timeline of %s:\n%s", trimOverlaps, participant, diff)
+			}
+			for _, conflict := range expected.Conflicts {
+				if isShared(conflict.Loser) {
+					t.Fatalf("trimOverlaps %v: expected %v to be dropped", trimOverlaps, conflict.Loser)
+				}
+			}
+		}
+	}
+}
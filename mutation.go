@@ -0,0 +1,117 @@
+package scheduleMerge
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidRank is returned when an event is inserted at a position outside the raw schedule.
+var ErrInvalidRank = errors.New("scheduleMerge: rank out of range")
+
+// Add adds the event to the raw schedule as its most desirable event (or as the most desirable event that is not
+// pinned, see Insert). If the engine has already merged, the next Merge only merges the new event into the existing
+// merged schedule.
+func (e *Engine) Add(event Event) {
+	// Appending cannot fail: len(e.RawSchedule) is always a valid rank.
+	_ = e.Insert(len(e.RawSchedule), event)
+}
+
+// Insert inserts the event into the raw schedule at the given rank, i.e. the position in the desirability order
+// (0 for the least desirable event, len(RawSchedule) for the most desirable one). If the event is less desirable
+// than an event that has already been merged, the next Merge merges the whole raw schedule again.
+//
+// Pinned events (see PolicyPinned) stay more desirable than all the other events: the rank of a pinned event is
+// raised to the least desirable pinned event and the rank of any other event is lowered to the most desirable event
+// that is not pinned.
+func (e *Engine) Insert(rank int, event Event) error {
+	if rank < 0 || rank > len(e.RawSchedule) {
+		return fmt.Errorf("%w: %d is not in [0, %d]", ErrInvalidRank, rank, len(e.RawSchedule))
+	}
+	// Pinned events stay more desirable than all the other events.
+	if firstPinned := e.firstPinned(); isPinned(event) && rank < firstPinned {
+		rank = firstPinned
+	} else if !isPinned(event) && rank > firstPinned {
+		rank = firstPinned
+	}
+
+	e.saveUndo()
+	e.RawSchedule = append(e.RawSchedule, nil)
+	copy(e.RawSchedule[rank+1:], e.RawSchedule[rank:])
+	e.RawSchedule[rank] = event
+	e.record(Op{Kind: OpInsert, Rank: rank, Event: event})
+
+	if rank < e.processed {
+		e.resetMerge()
+	}
+	e.mergingFinished = false
+	return nil
+}
+
+// Remove removes the event from the raw schedule. It reports whether the event was part of the raw schedule. If the
+// event has already been merged, the next Merge merges the whole raw schedule again.
+func (e *Engine) Remove(event Event) bool {
+	for rank, rawEvent := range e.RawSchedule {
+		if sameEvent(rawEvent, event) {
+			e.removeAt(rank)
+			return true
+		}
+	}
+
+	return false
+}
+
+// removeAt removes the raw event at the given rank.
+func (e *Engine) removeAt(rank int) {
+	e.saveUndo()
+	e.RawSchedule = append(e.RawSchedule[:rank:rank], e.RawSchedule[rank+1:]...)
+	e.record(Op{Kind: OpRemove, Rank: rank})
+	if rank < e.processed {
+		e.resetMerge()
+	}
+}
+
+// SetTrimOverlaps changes TrimOverlaps. If the setting changes, the next Merge merges the whole raw schedule again.
+// Changing the TrimOverlaps field directly does not affect a merge that has already started.
+func (e *Engine) SetTrimOverlaps(trimOverlaps bool) {
+	if e.TrimOverlaps == trimOverlaps {
+		return
+	}
+
+	e.record(Op{Kind: OpSetTrimOverlaps, TrimOverlaps: trimOverlaps})
+	e.saveUndo()
+	e.TrimOverlaps = trimOverlaps
+	e.resetMerge()
+}
+
+// SetLocation changes Location. If the location changes, the next Merge merges the whole raw schedule again.
+// Changing the Location field directly does not affect a merge that has already started.
+func (e *Engine) SetLocation(loc *time.Location) {
+	if e.Location == loc {
+		return
+	}
+
+	e.record(Op{Kind: OpSetLocation, Location: loc})
+	e.saveUndo()
+	e.Location = loc
+	e.resetMerge()
+}
+
+// resetMerge throws away the result of merging, so that the next Merge starts from scratch. The merged raw events and
+// the buffer of merge are reused, while the merged schedule, the conflicts and the violations get new memory, as the
+// caller might still hold them. The index of the parts is shared with the undo history (see state), so it is only
+// reused if there is none.
+func (e *Engine) resetMerge() {
+	clear(e.merged)
+	e.merged = e.merged[:0]
+	e.MergedSchedule = e.MergedSchedule[:0:0]
+	e.Conflicts = make([]Conflict, 0, cap(e.Conflicts))
+	e.Violations = make([]Violation, 0, cap(e.Violations))
+	if len(e.undo) == 0 && len(e.redo) == 0 {
+		clear(e.origins)
+	} else {
+		e.origins = nil
+	}
+	e.processed = 0
+	e.mergingFinished = false
+}
@@ -0,0 +1,132 @@
+package scheduleMerge
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEngine_Mutations(t *testing.T) {
+	newEvent := func(id, start, end int) *event {
+		return &event{
+			StartTime: time.Date(2020, 1, 1, start, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2020, 1, 1, end, 0, 0, 0, time.UTC),
+			ID:        id,
+		}
+	}
+	// merged returns the merged schedule of a fresh engine for the events (sorted by desirability).
+	merged := func(trimOverlaps bool, events ...*event) []event {
+		e := NewEngine(orderedSchedule(schedule(events).GetEvents()), trimOverlaps)
+		e.Merge()
+		return mergedEvents(e.MergedSchedule)
+	}
+
+	var (
+		a = newEvent(1, 0, 6)
+		b = newEvent(2, 2, 4)
+		c = newEvent(3, 3, 8)
+	)
+
+	tcs := []struct {
+		name     string
+		mutate   func(t *testing.T, e *Engine)
+		expected []event
+	}{
+		{
+			name: "add",
+			mutate: func(t *testing.T, e *Engine) {
+				e.Add(c)
+			},
+			expected: merged(true, a, b, c),
+		},
+		{
+			name: "insert as the least desirable event",
+			mutate: func(t *testing.T, e *Engine) {
+				if err := e.Insert(0, c); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			},
+			expected: merged(true, c, a, b),
+		},
+		{
+			name: "insert in the middle",
+			mutate: func(t *testing.T, e *Engine) {
+				if err := e.Insert(1, c); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			},
+			expected: merged(true, a, c, b),
+		},
+		{
+			name: "insert out of range",
+			mutate: func(t *testing.T, e *Engine) {
+				if err := e.Insert(3, c); !errors.Is(err, ErrInvalidRank) {
+					t.Fatalf("expected %v, got %v", ErrInvalidRank, err)
+				}
+			},
+			expected: merged(true, a, b),
+		},
+		{
+			name: "remove",
+			mutate: func(t *testing.T, e *Engine) {
+				if !e.Remove(b) {
+					t.Fatalf("expected the event to be removed")
+				}
+			},
+			expected: merged(true, a),
+		},
+		{
+			name: "remove unknown event",
+			mutate: func(t *testing.T, e *Engine) {
+				if e.Remove(c) {
+					t.Fatalf("expected the event not to be removed")
+				}
+			},
+			expected: merged(true, a, b),
+		},
+		{
+			name: "set trim overlaps",
+			mutate: func(t *testing.T, e *Engine) {
+				e.SetTrimOverlaps(false)
+			},
+			expected: merged(false, a, b),
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			e := NewEngine(orderedSchedule{a, b}, true)
+			e.Merge()
+			held := e.MergedSchedule
+			before := mergedEvents(held)
+			tc.mutate(t, e)
+			e.Merge()
+
+			if diff := cmp.Diff(tc.expected, mergedEvents(e.MergedSchedule)); diff != "" {
+				t.Fatalf("unexpected merged schedule:\n%s\n%s", diff, e.Timeline(TimelineOptions{}))
+			}
+			if diff := cmp.Diff(before, mergedEvents(held)); diff != "" {
+				t.Fatalf("expected the merged schedule taken before the change to stay as it is:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEngine_Merge_KeepsMergedSchedule(t *testing.T) {
+	e := NewEngine(orderedSchedule{
+		&event{StartTime: at(0), EndTime: at(6), ID: 1},
+		&event{StartTime: at(2), EndTime: at(4), ID: 2},
+	}, true)
+	e.Merge()
+	held := e.MergedSchedule
+	expected := mergedEvents(held)
+
+	e.Add(&event{StartTime: at(3), EndTime: at(8), ID: 3})
+	e.Merge()
+
+	if diff := cmp.Diff(expected, mergedEvents(held)); diff != "" {
+		t.Fatalf("expected the merged schedule taken before the merge to stay as it is:\n%s", diff)
+	}
+}
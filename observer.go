@@ -0,0 +1,44 @@
+package scheduleMerge
+
+// Observer is notified while the engine merges, e.g. to log the conflicts or to export metrics. Every func is
+// optional.
+type Observer struct {
+	// OnProgress is called with the number of raw events processed so far out of the total number of raw events, at
+	// the same points as Engine.OnProgress.
+	OnProgress func(processed, total int)
+	// OnConflict is called with every conflict once it is resolved. MergeParallel calls it once all the windows are
+	// reconciled, with the same conflicts in the same order as Merge.
+	OnConflict func(Conflict)
+	// OnViolation is called with every violation of the constraints once it is resolved (or found to be unresolvable).
+	OnViolation func(Violation)
+}
+
+// notifyProgress passes the number of processed raw events to the observers.
+func (e *Engine) notifyProgress(total int) {
+	for _, observer := range e.Observers {
+		if observer.OnProgress != nil {
+			observer.OnProgress(e.processed, total)
+		}
+	}
+}
+
+// notifyConflicts passes the conflicts to the observers.
+func (e *Engine) notifyConflicts(conflicts []Conflict) {
+	for _, observer := range e.Observers {
+		if observer.OnConflict == nil {
+			continue
+		}
+		for _, conflict := range conflicts {
+			observer.OnConflict(conflict)
+		}
+	}
+}
+
+// notifyViolation passes the violation to the observers.
+func (e *Engine) notifyViolation(violation Violation) {
+	for _, observer := range e.Observers {
+		if observer.OnViolation != nil {
+			observer.OnViolation(violation)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package scheduleMerge
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidOption is returned by New if an option or a combination of options is invalid.
+var ErrInvalidOption = errors.New("scheduleMerge: invalid option")
+
+// Strategy is how the Engine resolves an overlap between two events.
+type Strategy int
+
+const (
+	// StrategyTrim trims the less desirable event (see Engine.TrimOverlaps).
+	StrategyTrim Strategy = iota + 1
+	// StrategyDiscard discards the less desirable event.
+	StrategyDiscard
+)
+
+// String returns the name of the Strategy.
+func (s Strategy) String() string {
+	switch s {
+	case StrategyTrim:
+		return "trim"
+	case StrategyDiscard:
+		return "discard"
+	default:
+		return "unknown"
+	}
+}
+
+// config holds the options passed to New.
+type config struct {
+	strategy    Strategy
+	padding     time.Duration
+	granularity time.Duration
+	minFragment time.Duration
+	location    *time.Location
+	constraints []Constraint
+	observers   []Observer
+	undoLimit   int
+	cloneEvent  func(Event) Event
+}
+
+// Option configures an Engine created by New.
+type Option func(*config) error
+
+// WithStrategy sets how overlaps are resolved. Defaults to StrategyTrim.
+func WithStrategy(strategy Strategy) Option {
+	return func(c *config) error {
+		if strategy != StrategyTrim && strategy != StrategyDiscard {
+			return fmt.Errorf("%w: unknown strategy %d", ErrInvalidOption, strategy)
+		}
+		c.strategy = strategy
+		return nil
+	}
+}
+
+// WithPadding keeps at least the given time free between any two events of the merged schedule, e.g. to travel
+// between two appointments. The padding is taken from the less desirable of the two events, by adding a MinRest
+// constraint (see Engine.Constraints).
+func WithPadding(padding time.Duration) Option {
+	return func(c *config) error {
+		if padding <= 0 {
+			return fmt.Errorf("%w: padding %v is not positive", ErrInvalidOption, padding)
+		}
+		c.padding = padding
+		return nil
+	}
+}
+
+// WithGranularity rounds the bounds of the parts of trimmed events to multiples of the granularity (see
+// Engine.Granularity).
+func WithGranularity(granularity time.Duration) Option {
+	return func(c *config) error {
+		if granularity <= 0 {
+			return fmt.Errorf("%w: granularity %v is not positive", ErrInvalidOption, granularity)
+		}
+		c.granularity = granularity
+		return nil
+	}
+}
+
+// WithMinFragment drops the parts of trimmed events that are shorter than the given duration (see
+// Engine.MinFragment).
+func WithMinFragment(minFragment time.Duration) Option {
+	return func(c *config) error {
+		if minFragment <= 0 {
+			return fmt.Errorf("%w: minimum fragment %v is not positive", ErrInvalidOption, minFragment)
+		}
+		c.minFragment = minFragment
+		return nil
+	}
+}
+
+// WithObserver adds an Observer that is notified while the engine merges (see Engine.Observers).
+func WithObserver(observer Observer) Option {
+	return func(c *config) error {
+		if observer.OnProgress == nil && observer.OnConflict == nil && observer.OnViolation == nil {
+			return fmt.Errorf("%w: observer observes nothing", ErrInvalidOption)
+		}
+		c.observers = append(c.observers, observer)
+		return nil
+	}
+}
+
+// WithLocation sets the time zone the dates of AllDayEvent(s) are resolved in (see Engine.Location).
+func WithLocation(loc *time.Location) Option {
+	return func(c *config) error {
+		if loc == nil {
+			return fmt.Errorf("%w: location is nil", ErrInvalidOption)
+		}
+		c.location = loc
+		return nil
+	}
+}
+
+// WithConstraints adds rules the merged schedule has to satisfy (see Engine.Constraints).
+func WithConstraints(constraints ...Constraint) Option {
+	return func(c *config) error {
+		for i, constraint := range constraints {
+			if constraint == nil {
+				return fmt.Errorf("%w: constraint %d is nil", ErrInvalidOption, i)
+			}
+		}
+		c.constraints = append(c.constraints, constraints...)
+		return nil
+	}
+}
+
+// WithUndoLimit sets the number of changes Undo can revert (see Engine.UndoLimit).
+func WithUndoLimit(limit int) Option {
+	return func(c *config) error {
+		if limit < 0 {
+			return fmt.Errorf("%w: undo limit %d is negative", ErrInvalidOption, limit)
+		}
+		c.undoLimit = limit
+		return nil
+	}
+}
+
+// WithCloneEvent sets the function that creates the parts of trimmed events (see Engine.CloneEvent).
+func WithCloneEvent(clone func(Event) Event) Option {
+	return func(c *config) error {
+		if clone == nil {
+			return fmt.Errorf("%w: clone function is nil", ErrInvalidOption)
+		}
+		c.cloneEvent = clone
+		return nil
+	}
+}
+
+// New creates an Engine for the rawSchedule, configured by the options. The rawSchedule is sorted like NewEngine sorts
+// it. New returns an error wrapping ErrInvalidOption if an option is invalid, or if the options do not fit together:
+// the padding and the minimum fragment have to be multiples of the granularity, as the rounded bounds would not
+// leave them otherwise.
+func New(rawSchedule Schedule, options ...Option) (*Engine, error) {
+	c := config{strategy: StrategyTrim}
+	for _, option := range options {
+		if err := option(&c); err != nil {
+			return nil, err
+		}
+	}
+	if c.granularity > 0 && c.padding%c.granularity != 0 {
+		return nil, fmt.Errorf("%w: padding %v is not a multiple of the granularity %v", ErrInvalidOption,
+			c.padding, c.granularity)
+	}
+	if c.granularity > 0 && c.minFragment%c.granularity != 0 {
+		return nil, fmt.Errorf("%w: minimum fragment %v is not a multiple of the granularity %v", ErrInvalidOption,
+			c.minFragment, c.granularity)
+	}
+
+	e := NewEngine(rawSchedule, c.strategy == StrategyTrim)
+	e.Location = c.location
+	e.Granularity = c.granularity
+	e.MinFragment = c.minFragment
+	e.Constraints = c.constraints
+	if c.padding > 0 {
+		// Events that touch leave no time in between, so no gap is a break.
+		e.Constraints = append(e.Constraints, MinRest{Rest: c.padding, MaxBreak: -1})
+	}
+	e.Observers = c.observers
+	e.UndoLimit = c.undoLimit
+	e.CloneEvent = c.cloneEvent
+	return e, nil
+}
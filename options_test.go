@@ -0,0 +1,286 @@
+package scheduleMerge
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNew_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []Option
+	}{
+		{"unknown strategy", []Option{WithStrategy(Strategy(0))}},
+		{"negative padding", []Option{WithPadding(-time.Minute)}},
+		{"zero granularity", []Option{WithGranularity(0)}},
+		{"negative minimum fragment", []Option{WithMinFragment(-time.Minute)}},
+		{"empty observer", []Option{WithObserver(Observer{})}},
+		{"nil location", []Option{WithLocation(nil)}},
+		{"nil constraint", []Option{WithConstraints(MinRest{Rest: time.Hour}, nil)}},
+		{"negative undo limit", []Option{WithUndoLimit(-1)}},
+		{"nil clone", []Option{WithCloneEvent(nil)}},
+		{"padding not a multiple of the granularity", []Option{WithGranularity(15 * time.Minute), WithPadding(10 * time.Minute)}},
+		{"minimum fragment not a multiple of the granularity", []Option{WithMinFragment(time.Hour), WithGranularity(25 * time.Minute)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(randomSchedule(1, 10, 24), tt.options...)
+			if !errors.Is(err, ErrInvalidOption) {
+				t.Fatalf("expected ErrInvalidOption, got %v", err)
+			}
+			if e != nil {
+				t.Fatalf("expected no engine")
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, strategy := range []Strategy{0, StrategyTrim, StrategyDiscard} {
+		t.Run(strategy.String(), func(t *testing.T) {
+			var options []Option
+			if strategy != 0 {
+				options = append(options, WithStrategy(strategy))
+			}
+			e, err := New(randomSchedule(1, 50, 48), options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e.Merge()
+
+			expected := NewEngine(randomSchedule(1, 50, 48), strategy != StrategyDiscard)
+			expected.Merge()
+			if diff := cmp.Diff(mergedEvents(expected.MergedSchedule), mergedEvents(e.MergedSchedule)); diff != "" {
+				t.Fatalf("unexpected merged schedule:\n%s", diff)
+			}
+		})
+	}
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	clone := func(event Event) Event { return event.Clone() }
+	e, err := New(randomSchedule(1, 10, 24),
+		WithLocation(berlin),
+		WithGranularity(15*time.Minute),
+		WithMinFragment(30*time.Minute),
+		WithPadding(time.Hour),
+		WithConstraints(MaxDailyDuration{Limit: 8 * time.Hour}),
+		WithUndoLimit(5),
+		WithCloneEvent(clone),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Location != berlin || e.Granularity != 15*time.Minute || e.MinFragment != 30*time.Minute ||
+		e.UndoLimit != 5 || e.CloneEvent == nil {
+		t.Fatalf("expected the options to be applied")
+	}
+	expectedConstraints := []Constraint{MaxDailyDuration{Limit: 8 * time.Hour}, MinRest{Rest: time.Hour, MaxBreak: -1}}
+	if diff := cmp.Diff(expectedConstraints, e.Constraints); diff != "" {
+		t.Fatalf("unexpected constraints:\n%s", diff)
+	}
+}
+
+func TestNew_Padding(t *testing.T) {
+	origin := time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return origin.Add(time.Duration(minutes) * time.Minute)
+	}
+	e, err := New(schedule{
+		{StartTime: at(60), EndTime: at(120), CreatedAt: origin, ID: 1},
+		{StartTime: at(0), EndTime: at(60), CreatedAt: origin.Add(time.Minute), ID: 2},
+	}, WithPadding(30*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Merge()
+
+	expected := []event{
+		{StartTime: at(0), EndTime: at(60), CreatedAt: origin.Add(time.Minute), ID: 2},
+		{StartTime: at(90), EndTime: at(120), CreatedAt: origin, ID: 1},
+	}
+	if diff := cmp.Diff(expected, mergedEvents(e.MergedSchedule)); diff != "" {
+		t.Fatalf("unexpected merged schedule:\n%s", diff)
+	}
+}
+
+func TestNew_Padding_Refine(t *testing.T) {
+	origin := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return origin.Add(time.Duration(minutes) * time.Minute)
+	}
+	rawSchedule := schedule{
+		{StartTime: at(0), EndTime: at(45), CreatedAt: origin, ID: 1},
+		{StartTime: at(75), EndTime: at(180), CreatedAt: origin.Add(time.Minute), ID: 2},
+	}
+
+	e, err := New(rawSchedule, WithGranularity(15*time.Minute), WithMinFragment(30*time.Minute), WithPadding(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Merge()
+
+	// The padding leaves 15 minutes of the less desirable event, which is shorter than the minimum fragment.
+	expected := []event{{StartTime: at(75), EndTime: at(180), CreatedAt: origin.Add(time.Minute), ID: 2}}
+	if diff := cmp.Diff(expected, mergedEvents(e.MergedSchedule)); diff != "" {
+		t.Fatalf("unexpected merged schedule:\n%s", diff)
+	}
+}
+
+func TestEngine_Merge_Refine(t *testing.T) {
+	origin := time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return origin.Add(time.Duration(minutes) * time.Minute)
+	}
+	// less and more return the less and the more desirable of two raw events.
+	less := func(start, end int) *event {
+		return &event{StartTime: at(start), EndTime: at(end), CreatedAt: origin, ID: 1}
+	}
+	more := func(start, end int) *event {
+		return &event{StartTime: at(start), EndTime: at(end), CreatedAt: origin.Add(time.Minute), ID: 2}
+	}
+
+	tests := []struct {
+		name        string
+		raw         schedule
+		granularity time.Duration
+		minFragment time.Duration
+		expected    []event
+	}{
+		{
+			name:        "trimmed bounds are rounded inward",
+			raw:         schedule{less(0, 180), more(70, 110)},
+			granularity: 15 * time.Minute,
+			expected:    []event{*less(0, 60), *more(70, 110), *less(120, 180)},
+		},
+		{
+			name:        "bounds of raw events are kept",
+			raw:         schedule{less(7, 50), more(130, 170)},
+			granularity: 15 * time.Minute,
+			expected:    []event{*less(7, 50), *more(130, 170)},
+		},
+		{
+			name:        "parts left empty are dropped",
+			raw:         schedule{less(0, 180), more(5, 175)},
+			granularity: 15 * time.Minute,
+			expected:    []event{*more(5, 175)},
+		},
+		{
+			name:        "short parts are dropped",
+			raw:         schedule{less(0, 120), more(20, 60)},
+			minFragment: 30 * time.Minute,
+			expected:    []event{*more(20, 60), *less(60, 120)},
+		},
+		{
+			name:        "short raw events are kept",
+			raw:         schedule{less(0, 10), more(20, 25)},
+			minFragment: 30 * time.Minute,
+			expected:    []event{*less(0, 10), *more(20, 25)},
+		},
+		{
+			name:        "parts rounded below the minimum are dropped",
+			raw:         schedule{less(0, 120), more(50, 100)},
+			granularity: 15 * time.Minute,
+			minFragment: 30 * time.Minute,
+			expected:    []event{*less(0, 45), *more(50, 100)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, parallel := range []bool{false, true} {
+				e := NewEngine(tt.raw, true)
+				e.Granularity = tt.granularity
+				e.MinFragment = tt.minFragment
+				if parallel {
+					e.MergeParallel(2)
+				} else {
+					e.Merge()
+				}
+				if diff := cmp.Diff(tt.expected, mergedEvents(e.MergedSchedule)); diff != "" {
+					t.Fatalf("parallel %v: unexpected merged schedule:\n%s", parallel, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestEngine_Merge_Refine_Undo(t *testing.T) {
+	origin := time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC)
+	e := NewEngine(schedule{{StartTime: origin, EndTime: origin.Add(3 * time.Hour), CreatedAt: origin, ID: 1}}, true)
+	e.Granularity = time.Hour
+	e.UndoLimit = 1
+	e.Merge()
+	e.Add(&event{StartTime: origin.Add(90 * time.Minute), EndTime: origin.Add(2 * time.Hour), CreatedAt: origin.Add(time.Minute), ID: 2})
+	e.Merge()
+	e.Add(&event{StartTime: origin.Add(10 * time.Minute), EndTime: origin.Add(20 * time.Minute), CreatedAt: origin.Add(2 * time.Minute), ID: 3})
+	e.Merge()
+	if err := CheckInvariants(e.MergedSchedule); err != nil {
+		t.Fatal(err)
+	}
+
+	if !e.Undo() {
+		t.Fatalf("expected Undo to revert the last Add")
+	}
+	e.Merge()
+	expected := []event{
+		{StartTime: origin, EndTime: origin.Add(time.Hour), CreatedAt: origin, ID: 1},
+		{StartTime: origin.Add(90 * time.Minute), EndTime: origin.Add(2 * time.Hour), CreatedAt: origin.Add(time.Minute), ID: 2},
+		{StartTime: origin.Add(2 * time.Hour), EndTime: origin.Add(3 * time.Hour), CreatedAt: origin, ID: 1},
+	}
+	if diff := cmp.Diff(expected, mergedEvents(e.MergedSchedule)); diff != "" {
+		t.Fatalf("unexpected merged schedule after Undo:\n%s", diff)
+	}
+}
+
+func TestEngine_Observers(t *testing.T) {
+	type notifications struct {
+		progress   [][2]int
+		conflicts  []Conflict
+		violations []Violation
+	}
+	observe := func(n *notifications) Observer {
+		return Observer{
+			OnProgress:  func(processed, total int) { n.progress = append(n.progress, [2]int{processed, total}) },
+			OnConflict:  func(c Conflict) { n.conflicts = append(n.conflicts, c) },
+			OnViolation: func(v Violation) { n.violations = append(n.violations, v) },
+		}
+	}
+
+	for _, parallel := range []bool{false, true} {
+		var first, second notifications
+		e, err := New(randomSchedule(1, 300, 200),
+			WithPadding(time.Hour),
+			WithObserver(observe(&first)),
+			WithObserver(Observer{OnConflict: func(c Conflict) { second.conflicts = append(second.conflicts, c) }}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parallel {
+			e.MergeParallel(4)
+		} else {
+			e.Merge()
+		}
+
+		if len(e.Conflicts) == 0 || len(e.Violations) == 0 {
+			t.Fatalf("parallel %v: expected conflicts and violations", parallel)
+		}
+		if diff := cmp.Diff(e.Conflicts, first.conflicts); diff != "" {
+			t.Fatalf("parallel %v: unexpected notified conflicts:\n%s", parallel, diff)
+		}
+		if diff := cmp.Diff(e.Conflicts, second.conflicts); diff != "" {
+			t.Fatalf("parallel %v: unexpected conflicts notified to the second observer:\n%s", parallel, diff)
+		}
+		if diff := cmp.Diff(e.Violations, first.violations); diff != "" {
+			t.Fatalf("parallel %v: unexpected notified violations:\n%s", parallel, diff)
+		}
+		if last := first.progress[len(first.progress)-1]; last != [2]int{300, 300} {
+			t.Fatalf("parallel %v: expected the progress to end at 300 of 300, got %v", parallel, last)
+		}
+	}
+}
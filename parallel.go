@@ -0,0 +1,264 @@
+package scheduleMerge
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// minEventsPerWindow is the minimum number of raw events MergeParallel puts into a window. Smaller windows are not
+// worth the overhead of merging them concurrently.
+const minEventsPerWindow = 64
+
+// MergeParallel merges like Merge, but uses up to workers goroutines. If workers is not positive,
+// runtime.GOMAXPROCS(0) goroutines are used.
+//
+// The timeline is split into windows holding roughly the same number of raw events. The windows are merged
+// concurrently, and the parts of the events crossing the boundaries between windows are reconciled afterwards, so that
+// MergedSchedule and Conflicts are exactly the same as the ones Merge creates.
+//
+// MergeParallel falls back to Merge if the engine has already merged some raw events (e.g. after Add or after
+// MergeContext stopped early), if there are too few raw events to split, or if some raw events cannot be told apart
+// from their parts (see Engine).
+func (e *Engine) MergeParallel(workers int) {
+	if e.mergingFinished {
+		return
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if maxWorkers := len(e.RawSchedule) / minEventsPerWindow; workers > maxWorkers {
+		workers = maxWorkers
+	}
+	if workers < 2 || e.processed > 0 || !allKeyed(e.RawSchedule) {
+		e.Merge()
+		return
+	}
+
+	for _, rawEvent := range e.RawSchedule {
+		e.resolve(rawEvent)
+	}
+
+	windows := e.splitIntoWindows(workers)
+	var (
+		wg        sync.WaitGroup
+		semaphore = make(chan struct{}, workers)
+	)
+	for _, w := range windows {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(w *Engine) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			for _, rawEvent := range w.RawSchedule {
+				w.mergeRawEvent(rawEvent)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	e.reconcileWindows(windows)
+	e.notifyConflicts(e.Conflicts)
+	e.processed = len(e.RawSchedule)
+	e.publish()
+	e.refineAndEnforce()
+	e.reportProgress(len(e.RawSchedule))
+	e.mergingFinished = true
+}
+
+// splitIntoWindows splits the timeline into the given number of windows and returns an engine for each of them. Every
+// window engine gets the raw events overlapping its window, in the same order as the raw schedule. Raw events that
+// cross the boundaries of a window are clipped to it; the window engine knows the raw events its clips originate
+// from.
+func (e *Engine) splitIntoWindows(windows int) []*Engine {
+	// The boundaries between the windows are quantiles of the start times of the raw events.
+	starts := make([]time.Time, 0, len(e.RawSchedule))
+	for _, rawEvent := range e.RawSchedule {
+		if start := startOf(rawEvent); !isUnbounded(start) {
+			starts = append(starts, start)
+		}
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+
+	var boundaries []time.Time
+	for i := 1; i < windows && len(starts) > 0; i++ {
+		boundary := starts[i*len(starts)/windows]
+		if len(boundaries) == 0 || boundary.After(boundaries[len(boundaries)-1]) {
+			boundaries = append(boundaries, boundary)
+		}
+	}
+
+	engines := make([]*Engine, len(boundaries)+1)
+	for i := range engines {
+		engines[i] = &Engine{
+			TrimOverlaps: e.TrimOverlaps,
+			Location:     e.Location,
+			CloneEvent:   e.CloneEvent,
+			origins:      eventMap[Event]{},
+		}
+	}
+
+	for _, rawEvent := range e.RawSchedule {
+		start, end := startOf(rawEvent), endOf(rawEvent)
+		// The first window whose end boundary is after the start of the raw event.
+		first := sort.Search(len(boundaries), func(i int) bool { return boundaries[i].After(start) })
+		for i := first; i < len(engines); i++ {
+			windowStart, windowEnd := unboundedStart, unboundedEnd
+			if i > 0 {
+				windowStart = boundaries[i-1]
+			}
+			if i < len(boundaries) {
+				windowEnd = boundaries[i]
+			}
+			if !windowStart.Before(end) {
+				break
+			}
+
+			if !windowStart.After(start) && !windowEnd.Before(end) {
+				engines[i].RawSchedule = append(engines[i].RawSchedule, rawEvent)
+				continue
+			}
+
+			clip := e.cloneEvent(rawEvent)
+			if windowStart.After(start) {
+				clip.SetStartTime(windowStart.In(boundLocation(start, end)))
+			}
+			if windowEnd.Before(end) {
+				clip.SetEndTime(windowEnd.In(boundLocation(end, start)))
+			}
+			engines[i].origins.set(clip, rawEvent)
+			engines[i].RawSchedule = append(engines[i].RawSchedule, clip)
+		}
+	}
+
+	return engines
+}
+
+// reconcileWindows combines the merged schedules and the conflicts of the window engines into the ones Merge would
+// have created for the whole raw schedule.
+func (e *Engine) reconcileWindows(windows []*Engine) {
+	rank := rankByEvent(e.RawSchedule)
+
+	// Conflicts: the windows only know the overlaps inside of them. The overlaps of the same events that touch at a
+	// boundary between two windows are joined. Merge records the conflicts of the raw events in the order of their
+	// desirability, and the conflicts of a single raw event in time order.
+	var conflicts []Conflict
+	for _, w := range windows {
+		for _, conflict := range w.Conflicts {
+			conflict.Winner = w.original(conflict.Winner)
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		if rank.get(conflicts[i].Winner) != rank.get(conflicts[j].Winner) {
+			return rank.get(conflicts[i].Winner) < rank.get(conflicts[j].Winner)
+		}
+		return startOfConflict(conflicts[i]).Before(startOfConflict(conflicts[j]))
+	})
+
+	// A discarded event is discarded by the first (least desirable) event overlapping it. The windows that did not
+	// see that overlap might have recorded later ones, which Merge would not have seen, as the discarded event was
+	// already gone.
+	discardedBy := eventMap[Event]{}
+	e.Conflicts = make([]Conflict, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		if conflict.Resolution == Discarded {
+			if winner, ok := discardedBy.lookup(conflict.Loser); ok && !sameEvent(winner, conflict.Winner) {
+				continue
+			}
+			discardedBy.set(conflict.Loser, conflict.Winner)
+		}
+
+		if last := len(e.Conflicts) - 1; last >= 0 &&
+			sameEvent(e.Conflicts[last].Winner, conflict.Winner) &&
+			sameEvent(e.Conflicts[last].Loser, conflict.Loser) &&
+			!e.Conflicts[last].End.IsZero() &&
+			e.Conflicts[last].End.Equal(conflict.Start) {
+			e.Conflicts[last].End = conflict.End
+			continue
+		}
+		e.Conflicts = append(e.Conflicts, conflict)
+	}
+	// The windows only saw the parts of a raw event that a single overlap covers, so they did not discard it.
+	for i, conflict := range e.Conflicts {
+		if conflict.Resolution == Trimmed && covers(startOfConflict(conflict), endOfConflict(conflict), conflict.Loser) {
+			e.Conflicts[i].Resolution = Discarded
+		}
+	}
+
+	// Merged schedule: the parts of the same raw event that touch at a boundary between two windows are joined. A
+	// discarded raw event is dropped from all the windows, not only from the ones it was discarded in.
+	type run struct {
+		original   Event
+		start, end time.Time
+		parts      []Event
+	}
+	var (
+		runs    []run
+		clipped = eventMap[bool]{}
+	)
+	for _, w := range windows {
+		for _, clip := range w.RawSchedule {
+			if original, ok := w.origins.lookup(clip); ok {
+				clipped.set(original, true)
+			}
+		}
+
+		for _, part := range w.merged {
+			original := w.original(part)
+			if _, ok := discardedBy.lookup(original); ok {
+				continue
+			}
+
+			start, end := startOf(part), endOf(part)
+			if last := len(runs) - 1; last >= 0 && sameEvent(runs[last].original, original) && runs[last].end.Equal(start) {
+				runs[last].end = end
+				runs[last].parts = append(runs[last].parts, part)
+				continue
+			}
+			runs = append(runs, run{original: original, start: start, end: end, parts: []Event{part}})
+		}
+	}
+
+	e.merged = make([]Event, 0, len(runs))
+	for _, r := range runs {
+		switch {
+		case !clipped.get(r.original):
+			// The raw event lies within a single window, where it was merged like Merge would have.
+			part := r.parts[0]
+			if !sameEvent(part, r.original) {
+				e.setOrigin(part, r.original)
+			}
+			e.merged = append(e.merged, part)
+		case r.start.Equal(startOf(r.original)) && r.end.Equal(endOf(r.original)):
+			e.merged = append(e.merged, r.original)
+		default:
+			part := e.fragment(r.original)
+			if !r.start.Equal(startOf(r.original)) {
+				part.SetStartTime(r.start)
+			}
+			if !r.end.Equal(endOf(r.original)) {
+				part.SetEndTime(r.end)
+			}
+			e.merged = append(e.merged, part)
+		}
+	}
+}
+
+// startOfConflict returns the start of the overlap of the conflict, with a missing start before any other time.
+func startOfConflict(conflict Conflict) time.Time {
+	if conflict.Start.IsZero() {
+		return unboundedStart
+	}
+	return conflict.Start
+}
+
+// endOfConflict returns the end of the overlap of the conflict, with a missing end after any other time.
+func endOfConflict(conflict Conflict) time.Time {
+	if conflict.End.IsZero() {
+		return unboundedEnd
+	}
+	return conflict.End
+}
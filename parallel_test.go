@@ -0,0 +1,74 @@
+package scheduleMerge
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEngine_MergeParallel(t *testing.T) {
+	type conflict struct {
+		Winner, Loser event
+		Start, End    string
+		Resolution    Resolution
+	}
+	conflicts := func(e *Engine) []conflict {
+		cs := make([]conflict, len(e.Conflicts))
+		for i, c := range e.Conflicts {
+			cs[i] = conflict{
+				Winner:     *(c.Winner.(*event)),
+				Loser:      *(c.Loser.(*event)),
+				Start:      c.Start.String(),
+				End:        c.End.String(),
+				Resolution: c.Resolution,
+			}
+		}
+		return cs
+	}
+
+	seeds := int64(50)
+	if testing.Short() {
+		seeds = 5
+	}
+	for seed := int64(1); seed <= seeds; seed++ {
+		for _, trimOverlaps := range []bool{true, false} {
+			for _, workers := range []int{0, 2, 3, 8} {
+				n := 100 + int(seed)*20
+				t.Run(fmt.Sprintf("seed %d-trim %t-workers %d", seed, trimOverlaps, workers), func(t *testing.T) {
+					sequential := NewEngine(randomSchedule(seed, n, n/4), trimOverlaps)
+					sequential.Merge()
+
+					parallel := NewEngine(randomSchedule(seed, n, n/4), trimOverlaps)
+					parallel.MergeParallel(workers)
+
+					// cmp.Diff is slow on hundreds of events, so it only explains a difference.
+					if expected, got := mergedEvents(sequential.MergedSchedule), mergedEvents(parallel.MergedSchedule); !slices.Equal(expected, got) {
+						t.Fatalf("unexpected merged schedule:\n%s", cmp.Diff(expected, got))
+					}
+					if expected, got := conflicts(sequential), conflicts(parallel); !slices.Equal(expected, got) {
+						t.Fatalf("unexpected conflicts:\n%s", cmp.Diff(expected, got))
+					}
+					for _, mergedEvent := range parallel.MergedSchedule {
+						if got, expected := parallel.original(mergedEvent).(*event).ID, mergedEvent.(*event).ID; got != expected {
+							t.Fatalf("expected a part of event %d to originate from it, got event %d", expected, got)
+						}
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestEngine_MergeParallel_FallsBack(t *testing.T) {
+	e := NewEngine(randomSchedule(1, 10, 5), true)
+	e.MergeParallel(4)
+
+	expected := NewEngine(randomSchedule(1, 10, 5), true)
+	expected.Merge()
+
+	if diff := cmp.Diff(mergedEvents(expected.MergedSchedule), mergedEvents(e.MergedSchedule)); diff != "" {
+		t.Fatalf("unexpected merged schedule:\n%s", diff)
+	}
+}
@@ -0,0 +1,110 @@
+package scheduleMerge
+
+import (
+	"sort"
+)
+
+// Policy is how an event is treated when a more desirable event overlaps it.
+type Policy int
+
+const (
+	// PolicyDefault follows the TrimOverlaps setting of the Engine.
+	PolicyDefault Policy = iota
+	// PolicyTrimmable events are trimmed, even if the Engine does not trim overlaps.
+	PolicyTrimmable
+	// PolicyAtomic events are never split: they are kept whole or discarded, even if the Engine trims overlaps.
+	PolicyAtomic
+	// PolicyPinned events are more desirable than all the events that are not pinned, wherever the Schedule sorts
+	// them. If two pinned events collide, the more desirable one (by the order of the Schedule) wins, and the less
+	// desirable one is trimmed or discarded according to the TrimOverlaps setting of the Engine.
+	PolicyPinned
+)
+
+// String returns the name of the Policy.
+func (p Policy) String() string {
+	switch p {
+	case PolicyDefault:
+		return "default"
+	case PolicyTrimmable:
+		return "trimmable"
+	case PolicyAtomic:
+		return "atomic"
+	case PolicyPinned:
+		return "pinned"
+	default:
+		return "unknown"
+	}
+}
+
+// PolicyEvent is an optional interface for Event(s) that declare their own Policy. Event(s) that do not implement it
+// have the PolicyDefault. The parts of a trimmed PolicyEvent have the Policy of the raw event.
+type PolicyEvent interface {
+	Event
+	// GetPolicy returns the Policy of the Event.
+	GetPolicy() Policy
+}
+
+// policyOf returns the Policy of the event.
+func policyOf(event Event) Policy {
+	if policyEvent, ok := event.(PolicyEvent); ok {
+		return policyEvent.GetPolicy()
+	}
+	return PolicyDefault
+}
+
+// isPinned reports whether the event has the PolicyPinned.
+func isPinned(event Event) bool {
+	return policyOf(event) == PolicyPinned
+}
+
+// trimsWith reports whether an event losing an overlap is trimmed (true) or discarded (false).
+func trimsWith(event Event, trimOverlaps bool) bool {
+	switch policyOf(event) {
+	case PolicyTrimmable:
+		return true
+	case PolicyAtomic:
+		return false
+	default:
+		return trimOverlaps
+	}
+}
+
+// trims reports whether the engine trims the merged event when it loses an overlap, according to the Policy of the
+// raw event it originates from.
+func (e *Engine) trims(mergedEvent Event) bool {
+	return trimsWith(e.original(mergedEvent), e.TrimOverlaps)
+}
+
+// pinLast moves the pinned events to the end of the events, keeping the order of the pinned and of the other events.
+// Events that are already in that order are not written to, as they might be shared (e.g. by a Snapshot).
+func pinLast(events []Event) {
+	sorted := sort.SliceIsSorted(events, func(i, j int) bool {
+		return !isPinned(events[i]) && isPinned(events[j])
+	})
+	if sorted {
+		return
+	}
+
+	var pinned []Event
+	n := 0
+	for _, event := range events {
+		if isPinned(event) {
+			pinned = append(pinned, event)
+			continue
+		}
+		events[n] = event
+		n++
+	}
+	copy(events[n:], pinned)
+}
+
+// firstPinned returns the rank of the least desirable pinned event in the raw schedule, or its length if no event is
+// pinned.
+func (e *Engine) firstPinned() int {
+	for rank := len(e.RawSchedule); rank > 0; rank-- {
+		if !isPinned(e.RawSchedule[rank-1]) {
+			return rank
+		}
+	}
+	return 0
+}
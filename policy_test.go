@@ -0,0 +1,271 @@
+package scheduleMerge
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type policyEvent struct {
+	event
+	Policy Policy
+}
+
+func (e *policyEvent) GetPolicy() Policy {
+	return e.Policy
+}
+
+func (e *policyEvent) Clone() Event {
+	clone := *e
+	return &clone
+}
+
+// policyEvents dereferences the merged events so that they can be compared by value.
+func policyEvents(merged []Event) []policyEvent {
+	evs := make([]policyEvent, len(merged))
+	for i := range merged {
+		evs[i] = *(merged[i].(*policyEvent))
+	}
+	return evs
+}
+
+// randomPolicySchedule returns a random schedule (see randomSchedule) with random policies.
+func randomPolicySchedule(seed int64, n, hours int) []Event {
+	r := rand.New(rand.NewSource(-seed))
+	events := make([]Event, n)
+	for i, ev := range randomSchedule(seed, n, hours) {
+		events[i] = &policyEvent{event: *ev, Policy: Policy(r.Intn(4))}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].(*policyEvent).CreatedAt.Before(events[j].(*policyEvent).CreatedAt)
+	})
+	return events
+}
+
+func TestEngine_Merge_Policy(t *testing.T) {
+	origin := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	ev := func(id, start, end int, policy Policy) *policyEvent {
+		return &policyEvent{event: event{StartTime: at(start), EndTime: at(end), ID: id}, Policy: policy}
+	}
+	// span describes a merged event by its ID and bounds.
+	type span struct {
+		ID         int
+		Start, End int
+	}
+
+	tests := []struct {
+		name         string
+		trimOverlaps bool
+		// raw is sorted by desirability in ascending order.
+		raw         []*policyEvent
+		expected    []span
+		resolutions []Resolution
+	}{
+		{
+			name:         "trimmable-no trim",
+			trimOverlaps: false,
+			raw:          []*policyEvent{ev(1, 0, 4, PolicyTrimmable), ev(2, 2, 6, PolicyDefault)},
+			expected:     []span{{1, 0, 2}, {2, 2, 6}},
+			resolutions:  []Resolution{Trimmed},
+		},
+		{
+			name:         "atomic-trim",
+			trimOverlaps: true,
+			raw:          []*policyEvent{ev(1, 0, 4, PolicyAtomic), ev(2, 2, 6, PolicyDefault)},
+			expected:     []span{{2, 2, 6}},
+			resolutions:  []Resolution{Discarded},
+		},
+		{
+			name:         "atomic-within-trim",
+			trimOverlaps: true,
+			raw:          []*policyEvent{ev(1, 0, 6, PolicyAtomic), ev(2, 2, 4, PolicyDefault), ev(3, 5, 8, PolicyDefault)},
+			expected:     []span{{2, 2, 4}, {3, 5, 8}},
+			resolutions:  []Resolution{Discarded},
+		},
+		{
+			name:         "discarded atomic event still trims less desirable events",
+			trimOverlaps: true,
+			raw:          []*policyEvent{ev(1, 0, 6, PolicyDefault), ev(2, 2, 4, PolicyAtomic), ev(3, 3, 5, PolicyDefault)},
+			expected:     []span{{1, 0, 2}, {3, 3, 5}, {1, 5, 6}},
+			resolutions:  []Resolution{Trimmed, Discarded, Trimmed},
+		},
+		{
+			name:         "pinned-trim",
+			trimOverlaps: true,
+			raw:          []*policyEvent{ev(1, 0, 4, PolicyPinned), ev(2, 2, 6, PolicyDefault)},
+			expected:     []span{{1, 0, 4}, {2, 4, 6}},
+			resolutions:  []Resolution{Trimmed},
+		},
+		{
+			name:         "pinned-no trim",
+			trimOverlaps: false,
+			raw:          []*policyEvent{ev(1, 2, 4, PolicyPinned), ev(2, 0, 6, PolicyAtomic)},
+			expected:     []span{{1, 2, 4}},
+			resolutions:  []Resolution{Discarded},
+		},
+		{
+			name:         "two pinned events-trim",
+			trimOverlaps: true,
+			raw:          []*policyEvent{ev(1, 0, 4, PolicyPinned), ev(2, 2, 6, PolicyDefault), ev(3, 3, 5, PolicyPinned)},
+			expected:     []span{{1, 0, 3}, {3, 3, 5}, {2, 5, 6}},
+			resolutions:  []Resolution{Trimmed, Trimmed, Trimmed},
+		},
+		{
+			name:         "two pinned events-no trim",
+			trimOverlaps: false,
+			raw:          []*policyEvent{ev(1, 0, 4, PolicyPinned), ev(2, 3, 5, PolicyPinned)},
+			expected:     []span{{2, 3, 5}},
+			resolutions:  []Resolution{Discarded},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := make(orderedSchedule, len(tt.raw))
+			for i, ev := range tt.raw {
+				raw[i] = ev
+			}
+			e := NewEngine(raw, tt.trimOverlaps)
+			e.Merge()
+
+			var got []span
+			for _, mergedEvent := range e.MergedSchedule {
+				pe := mergedEvent.(*policyEvent)
+				got = append(got, span{ID: pe.ID, Start: int(pe.StartTime.Sub(origin).Hours()), End: int(pe.EndTime.Sub(origin).Hours())})
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("unexpected merged schedule:\n%s", diff)
+			}
+
+			var resolutions []Resolution
+			for _, conflict := range e.Conflicts {
+				resolutions = append(resolutions, conflict.Resolution)
+			}
+			if diff := cmp.Diff(tt.resolutions, resolutions); diff != "" {
+				t.Errorf("unexpected resolutions:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEngine_Insert_Pinned(t *testing.T) {
+	origin := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	pinned := &policyEvent{event: event{StartTime: origin, EndTime: origin.Add(time.Hour), ID: 1}, Policy: PolicyPinned}
+	e := NewEngine(orderedSchedule{pinned, &event{StartTime: origin, EndTime: origin.Add(time.Hour), ID: 2}}, true)
+	if e.RawSchedule[1] != pinned {
+		t.Fatalf("expected the pinned event to be the most desirable")
+	}
+
+	e.Add(&event{StartTime: origin, EndTime: origin.Add(time.Hour), ID: 3})
+	if e.RawSchedule[2] != pinned {
+		t.Fatalf("expected the pinned event to stay the most desirable")
+	}
+
+	other := &policyEvent{event: event{StartTime: origin, EndTime: origin.Add(time.Hour), ID: 4}, Policy: PolicyPinned}
+	if err := e.Insert(0, other); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.RawSchedule[2] != other || e.RawSchedule[3] != pinned {
+		t.Fatalf("expected the inserted pinned event to be the least desirable pinned event")
+	}
+}
+
+func TestMerge_Policy_Random(t *testing.T) {
+	// At any hour, the merged schedule holds the most desirable event covering it, unless that event is not trimmed
+	// and overlaps a more desirable event.
+	bruteForce := func(raw []Event, trimOverlaps bool, hours int) []int {
+		owners := make([]int, hours+6)
+		for hour := range owners {
+			at := time.Date(2020, 1, 1, hour, 0, 0, 0, time.UTC)
+			for rank := len(raw) - 1; rank >= 0; rank-- {
+				ev := raw[rank]
+				if ev.GetStartTime().After(at) || !ev.GetEndTime().After(at) {
+					continue
+				}
+				if !trimsWith(ev, trimOverlaps) {
+					for _, more := range raw[rank+1:] {
+						if more.GetStartTime().Before(ev.GetEndTime()) && ev.GetStartTime().Before(more.GetEndTime()) {
+							ev = nil
+							break
+						}
+					}
+				}
+				if ev != nil {
+					owners[hour] = ev.(*policyEvent).ID
+				}
+				break
+			}
+		}
+		return owners
+	}
+	ownersOf := func(merged []Event, hours int) []int {
+		owners := make([]int, hours+6)
+		for _, ev := range merged {
+			for at := ev.GetStartTime(); at.Before(ev.GetEndTime()); at = at.Add(time.Hour) {
+				owners[int(at.Sub(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)).Hours())] = ev.(*policyEvent).ID
+			}
+		}
+		return owners
+	}
+
+	for seed := int64(1); seed <= 100; seed++ {
+		for _, trimOverlaps := range []bool{true, false} {
+			t.Run(fmt.Sprintf("seed %d-trim %t", seed, trimOverlaps), func(t *testing.T) {
+				const n, hours = 300, 100
+				e := NewEngine(orderedSchedule(randomPolicySchedule(seed, n, hours)), trimOverlaps)
+				e.Merge()
+				if diff := cmp.Diff(bruteForce(e.RawSchedule, trimOverlaps, hours), ownersOf(e.MergedSchedule, hours)); diff != "" {
+					t.Fatalf("unexpected owners:\n%s", diff)
+				}
+
+				parallel := NewEngine(orderedSchedule(randomPolicySchedule(seed, n, hours)), trimOverlaps)
+				parallel.MergeParallel(3)
+				if diff := cmp.Diff(policyEvents(e.MergedSchedule), policyEvents(parallel.MergedSchedule), cmp.AllowUnexported(policyEvent{})); diff != "" {
+					t.Fatalf("unexpected parallel merged schedule:\n%s", diff)
+				}
+				conflicts := func(e *Engine) []string {
+					var cs []string
+					for _, c := range e.Conflicts {
+						cs = append(cs, fmt.Sprintf("%d>%d [%v, %v) %v", c.Winner.(*policyEvent).ID,
+							c.Loser.(*policyEvent).ID, c.Start, c.End, c.Resolution))
+					}
+					return cs
+				}
+				if diff := cmp.Diff(conflicts(e), conflicts(parallel)); diff != "" {
+					t.Fatalf("unexpected parallel conflicts:\n%s", diff)
+				}
+
+				// The stream breaks ties in desirability by arrival, so the engine gets the events in that order.
+				byStart := randomPolicySchedule(seed, n, hours)
+				sort.SliceStable(byStart, func(i, j int) bool {
+					return byStart[i].GetStartTime().Before(byStart[j].GetStartTime())
+				})
+				expected := NewEngine(orderedSchedule(append([]Event(nil), byStart...)), trimOverlaps)
+				sort.SliceStable(expected.RawSchedule, func(i, j int) bool {
+					return expected.RawSchedule[i].(*policyEvent).CreatedAt.Before(expected.RawSchedule[j].(*policyEvent).CreatedAt)
+				})
+				pinLast(expected.RawSchedule)
+				expected.Merge()
+
+				s := NewStreamMerger(func(a, b Event) bool {
+					return a.(*policyEvent).CreatedAt.Before(b.(*policyEvent).CreatedAt)
+				}, trimOverlaps)
+				var streamed []Event
+				for _, ev := range byStart {
+					merged, err := s.Push(ev)
+					if err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+					streamed = append(streamed, merged...)
+				}
+				streamed = append(streamed, s.Flush()...)
+				if diff := cmp.Diff(policyEvents(expected.MergedSchedule), policyEvents(streamed), cmp.AllowUnexported(policyEvent{})); diff != "" {
+					t.Fatalf("unexpected streamed merged schedule:\n%s", diff)
+				}
+			})
+		}
+	}
+}
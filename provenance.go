@@ -0,0 +1,91 @@
+package scheduleMerge
+
+import (
+	"time"
+)
+
+// Provenance describes where an event of the merged schedule comes from.
+type Provenance struct {
+	// Event is the event of the merged schedule.
+	Event Event
+	// Original is the raw event the merged event originates from. It is the merged event itself, unless the raw event
+	// was trimmed.
+	Original Event
+	// Fragment is the position of the merged event among the parts of Original in the merged schedule, in time order.
+	Fragment int
+	// Fragments is the number of parts of Original in the merged schedule.
+	Fragments int
+	// Start is the start time of Original. It is the zero Time if Original has no start (see UnboundedEvent).
+	Start time.Time
+	// End is the end time of Original. It is the zero Time if Original has no end (see UnboundedEvent).
+	End time.Time
+	// Source is the Source of the Layer Original comes from. It is empty if Original does not come from a Layer.
+	Source string
+}
+
+// Trimmed reports whether the merged event is only a part of Original.
+func (p Provenance) Trimmed() bool {
+	return !sameEvent(p.Event, p.Original)
+}
+
+// Provenances returns the Provenance of every event of the merged schedule, in the same order as MergedSchedule.
+func (e *Engine) Provenances() []Provenance {
+	return provenances(e.MergedSchedule, e.original, e.sources)
+}
+
+// Provenance returns the Provenance of the event of the merged schedule. It reports false if the event is not part of
+// the merged schedule.
+func (e *Engine) Provenance(mergedEvent Event) (Provenance, bool) {
+	for i, m := range e.MergedSchedule {
+		if sameEvent(m, mergedEvent) {
+			return e.Provenances()[i], true
+		}
+	}
+	return Provenance{}, false
+}
+
+// Fragments returns the parts of the raw event in the merged schedule, in time order. It is empty if the raw event
+// was discarded or trimmed away completely.
+func (e *Engine) Fragments(rawEvent Event) []Event {
+	var fragments []Event
+	for _, mergedEvent := range e.MergedSchedule {
+		if sameEvent(e.original(mergedEvent), rawEvent) {
+			fragments = append(fragments, mergedEvent)
+		}
+	}
+	return fragments
+}
+
+// Provenances returns the Provenance of every event of the merged schedule of the Snapshot. See Engine.Provenances.
+func (s *Snapshot) Provenances() []Provenance {
+	return provenances(s.MergedSchedule, func(event Event) Event {
+		if origin, ok := s.origins.lookup(event); ok {
+			return origin
+		}
+		return event
+	}, s.sources)
+}
+
+// provenances returns the Provenance of every event of the merged schedule.
+func provenances(mergedSchedule []Event, original func(Event) Event, sources eventMap[string]) []Provenance {
+	var (
+		result    = make([]Provenance, len(mergedSchedule))
+		fragments = eventMap[int]{}
+	)
+	for i, mergedEvent := range mergedSchedule {
+		o := original(mergedEvent)
+		result[i] = Provenance{
+			Event:    mergedEvent,
+			Original: o,
+			Fragment: fragments.get(o),
+			Start:    exposedBound(startOf(o)),
+			End:      exposedBound(endOf(o)),
+			Source:   sources.get(o),
+		}
+		fragments.set(o, fragments.get(o)+1)
+	}
+	for i := range result {
+		result[i].Fragments = fragments.get(result[i].Original)
+	}
+	return result
+}
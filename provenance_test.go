@@ -0,0 +1,65 @@
+package scheduleMerge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEngine_Provenances(t *testing.T) {
+	var (
+		booking = &event{StartTime: at(0), EndTime: at(6), CreatedAt: at(0), ID: 1}
+		meeting = &event{StartTime: at(2), EndTime: at(3), CreatedAt: at(1), ID: 2}
+		call    = &event{StartTime: at(4), EndTime: at(5), CreatedAt: at(2), ID: 3}
+	)
+	e := NewEngine(schedule{booking, meeting, call}, true)
+	e.Merge()
+
+	// provenance describes a Provenance by the IDs of the events.
+	type provenance struct {
+		ID, Original           int
+		Fragment, Fragments    int
+		Start, End             time.Time
+		Trimmed                bool
+		MergedStart, MergedEnd time.Time
+	}
+	var got []provenance
+	for _, p := range e.Provenances() {
+		got = append(got, provenance{
+			ID:          p.Event.(*event).ID,
+			Original:    p.Original.(*event).ID,
+			Fragment:    p.Fragment,
+			Fragments:   p.Fragments,
+			Start:       p.Start,
+			End:         p.End,
+			Trimmed:     p.Trimmed(),
+			MergedStart: p.Event.GetStartTime(),
+			MergedEnd:   p.Event.GetEndTime(),
+		})
+	}
+	expected := []provenance{
+		{ID: 1, Original: 1, Fragment: 0, Fragments: 3, Start: at(0), End: at(6), Trimmed: true, MergedStart: at(0), MergedEnd: at(2)},
+		{ID: 2, Original: 2, Fragment: 0, Fragments: 1, Start: at(2), End: at(3), MergedStart: at(2), MergedEnd: at(3)},
+		{ID: 1, Original: 1, Fragment: 1, Fragments: 3, Start: at(0), End: at(6), Trimmed: true, MergedStart: at(3), MergedEnd: at(4)},
+		{ID: 3, Original: 3, Fragment: 0, Fragments: 1, Start: at(4), End: at(5), MergedStart: at(4), MergedEnd: at(5)},
+		{ID: 1, Original: 1, Fragment: 2, Fragments: 3, Start: at(0), End: at(6), Trimmed: true, MergedStart: at(5), MergedEnd: at(6)},
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Fatalf("unexpected provenances:\n%s", diff)
+	}
+
+	if fragments := e.Fragments(booking); len(fragments) != 3 || fragments[1] != e.MergedSchedule[2] {
+		t.Fatalf("expected the 3 fragments of the booking, got %v", fragments)
+	}
+	if p, ok := e.Provenance(e.MergedSchedule[4]); !ok || p.Original != booking || p.Fragment != 2 {
+		t.Fatalf("expected the last fragment of the booking, got %v", p)
+	}
+	if _, ok := e.Provenance(booking); ok {
+		t.Fatalf("expected the trimmed booking not to be part of the merged schedule")
+	}
+
+	if diff := cmp.Diff(e.Provenances(), e.Snapshot().Provenances()); diff != "" {
+		t.Fatalf("unexpected snapshot provenances:\n%s", diff)
+	}
+}
@@ -0,0 +1,150 @@
+package scheduleMerge
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// referenceMerge is a deliberately simple merge to check the Engine against. It takes the raw schedule sorted by
+// desirability in ascending order (pinned events last, see RawSchedule) and splits the time into the elementary
+// intervals between all the bounds of the raw events. Every elementary interval is owned by the most desirable raw
+// event covering it. A raw event that is not trimmed (see Policy) is discarded if any more desirable raw event
+// overlaps it; its intervals stay empty. The merged schedule consists of the runs of consecutive intervals of the
+// same owner that was not discarded. An owner that owns all of its intervals is kept as it is; otherwise every run
+// becomes a clone of it.
+//
+// It runs in O(n²) time for n raw events.
+func referenceMerge(rawSchedule []Event, trimOverlaps bool) []Event {
+	var bounds []time.Time
+	for _, rawEvent := range rawSchedule {
+		bounds = append(bounds, startOf(rawEvent), endOf(rawEvent))
+	}
+	sort.Slice(bounds, func(i, j int) bool {
+		return bounds[i].Before(bounds[j])
+	})
+
+	discarded := make([]bool, len(rawSchedule))
+	for less, lessEvent := range rawSchedule {
+		if trimsWith(lessEvent, trimOverlaps) {
+			continue
+		}
+		for _, moreEvent := range rawSchedule[less+1:] {
+			if startOf(moreEvent).Before(endOf(lessEvent)) && startOf(lessEvent).Before(endOf(moreEvent)) {
+				discarded[less] = true
+				break
+			}
+		}
+	}
+
+	// run is a run of consecutive intervals owned by the raw event with the given rank.
+	type run struct {
+		rank       int
+		start, end time.Time
+	}
+	var runs []run
+	for i := 0; i+1 < len(bounds); i++ {
+		start, end := bounds[i], bounds[i+1]
+		if !start.Before(end) {
+			continue
+		}
+
+		owner := -1
+		for rank, rawEvent := range rawSchedule {
+			if !start.Before(startOf(rawEvent)) && !endOf(rawEvent).Before(end) {
+				owner = rank
+			}
+		}
+		if owner < 0 || discarded[owner] {
+			continue
+		}
+		if n := len(runs); n > 0 && runs[n-1].rank == owner && runs[n-1].end.Equal(start) {
+			runs[n-1].end = end
+			continue
+		}
+		runs = append(runs, run{rank: owner, start: start, end: end})
+	}
+
+	merged := []Event{}
+	for _, r := range runs {
+		rawEvent := rawSchedule[r.rank]
+		rawStart, rawEnd := startOf(rawEvent), endOf(rawEvent)
+		if r.start.Equal(rawStart) && r.end.Equal(rawEnd) {
+			merged = append(merged, rawEvent)
+			continue
+		}
+
+		part := rawEvent.Clone()
+		if !r.start.Equal(rawStart) {
+			part.SetStartTime(r.start.In(boundLocation(rawStart, rawEnd)))
+		}
+		if !r.end.Equal(rawEnd) {
+			part.SetEndTime(r.end.In(boundLocation(rawEnd, rawStart)))
+		}
+		merged = append(merged, part)
+	}
+	return merged
+}
+
+func TestEngine_Merge_Reference(t *testing.T) {
+	// span describes a merged event by its ID and bounds, and whether it is the raw event itself.
+	type span struct {
+		ID         int
+		Start, End time.Time
+		Raw        bool
+	}
+	spans := func(merged []Event, raw map[Event]bool) []span {
+		var result []span
+		for _, mergedEvent := range merged {
+			var id int
+			switch ev := mergedEvent.(type) {
+			case *event:
+				id = ev.ID
+			case *policyEvent:
+				id = ev.ID
+			}
+			result = append(result, span{
+				ID:    id,
+				Start: mergedEvent.GetStartTime(),
+				End:   mergedEvent.GetEndTime(),
+				Raw:   raw[mergedEvent],
+			})
+		}
+		return result
+	}
+
+	for seed := int64(1); seed <= 300; seed++ {
+		for _, trimOverlaps := range []bool{true, false} {
+			n := int(seed%50) + 1
+			for _, rawSchedule := range []Schedule{randomSchedule(seed, n, 72), orderedSchedule(randomPolicySchedule(seed, n, 72))} {
+				e := NewEngine(rawSchedule, trimOverlaps)
+				raw := map[Event]bool{}
+				for _, rawEvent := range e.RawSchedule {
+					raw[rawEvent] = true
+				}
+				expected := spans(referenceMerge(e.RawSchedule, trimOverlaps), raw)
+
+				e.Merge()
+				if diff := cmp.Diff(expected, spans(e.MergedSchedule, raw)); diff != "" {
+					t.Fatalf("seed %d, trimOverlaps %v: unexpected merged schedule:\n%s", seed, trimOverlaps, diff)
+				}
+			}
+		}
+	}
+}
+
+func FuzzEngine_Merge_Reference(f *testing.F) {
+	f.Add([]byte{0, 10, 1, 5, 10, 2}, true)
+	f.Add([]byte{0, 60, 2, 10, 5, 1, 20, 5, 3, 0, 60, 2}, false)
+
+	f.Fuzz(func(t *testing.T, data []byte, trimOverlaps bool) {
+		e := NewEngine(fuzzSchedule(data), trimOverlaps)
+		expected := mergedEvents(referenceMerge(e.RawSchedule, trimOverlaps))
+		e.Merge()
+		if diff := cmp.Diff(expected, mergedEvents(e.MergedSchedule)); diff != "" {
+			t.Fatalf("unexpected merged schedule:\n%s", diff)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package scheduleMerge
+
+import "time"
+
+// refineAndEnforce refines the parts of the merged schedule and enforces the Constraints. The parts are refined before
+// the constraints are enforced, so that fragments that are dropped anyway cause no cuts, and again afterwards, as
+// cutting time leaves new parts.
+func (e *Engine) refineAndEnforce() {
+	e.refineParts()
+	e.enforceConstraints()
+	e.refineParts()
+}
+
+// refineParts rounds the trimmed bounds of the parts in the merged schedule to multiples of Granularity and drops the
+// parts shorter than MinFragment (or left empty by the rounding). The bounds of the raw events themselves are kept.
+// Rounded parts are replaced by new parts, as the undo history might still hold on to the old ones.
+func (e *Engine) refineParts() {
+	if e.Granularity <= 0 && e.MinFragment <= 0 {
+		return
+	}
+
+	// The merged schedule is refined in place, as it is a copy of the merge (see publish).
+	refined := e.MergedSchedule[:0]
+	for _, mergedEvent := range e.MergedSchedule {
+		if part, keep := e.refinePart(mergedEvent); keep {
+			refined = append(refined, part)
+		}
+	}
+	clear(e.MergedSchedule[len(refined):])
+	e.MergedSchedule = refined
+}
+
+// refinePart returns the merged event with its trimmed bounds rounded inward to multiples of Granularity. It reports
+// false if nothing (or less than MinFragment) is left of it. Raw events are returned as they are.
+func (e *Engine) refinePart(mergedEvent Event) (Event, bool) {
+	original, ok := e.origins.lookup(mergedEvent)
+	if !ok {
+		return mergedEvent, true
+	}
+
+	start, end := startOf(mergedEvent), endOf(mergedEvent)
+	refinedStart, refinedEnd := start, end
+	if e.Granularity > 0 {
+		if !isUnbounded(start) && !start.Equal(startOf(original)) {
+			refinedStart = ceilTime(start, e.Granularity)
+		}
+		if !isUnbounded(end) && !end.Equal(endOf(original)) {
+			refinedEnd = end.Truncate(e.Granularity)
+		}
+	}
+	if !refinedStart.Before(refinedEnd) {
+		return nil, false
+	}
+	if e.MinFragment > 0 && !isUnbounded(refinedStart) && !isUnbounded(refinedEnd) &&
+		refinedEnd.Sub(refinedStart) < e.MinFragment {
+		return nil, false
+	}
+	if refinedStart.Equal(start) && refinedEnd.Equal(end) {
+		return mergedEvent, true
+	}
+
+	part := e.fragment(mergedEvent)
+	if !refinedStart.Equal(start) {
+		part.SetStartTime(refinedStart.In(boundLocation(start, end)))
+	}
+	if !refinedEnd.Equal(end) {
+		part.SetEndTime(refinedEnd.In(boundLocation(end, start)))
+	}
+	return part, true
+}
+
+// ceilTime rounds t up to a multiple of d since the zero time (see time.Time.Truncate).
+func ceilTime(t time.Time, d time.Duration) time.Time {
+	if truncated := t.Truncate(d); truncated.Before(t) {
+		return truncated.Add(d)
+	}
+	return t
+}
@@ -0,0 +1,193 @@
+package scheduleMerge
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// defaultTimelineWidth is the number of columns used by RenderTimeline when neither a Scale nor a Width is set.
+const defaultTimelineWidth = 60
+
+// TimelineOptions configures how RenderTimeline draws a timeline.
+type TimelineOptions struct {
+	// Scale is the duration represented by a single column. If it is zero, the scale is chosen so that the whole
+	// timeline fits into Width columns.
+	Scale time.Duration
+	// Width is the number of columns the timeline should fit into when Scale is zero. Defaults to 60.
+	Width int
+	// Unicode selects box-drawing characters (├───┤) instead of the ASCII notation ([---)).
+	Unicode bool
+}
+
+// timelineGlyphs holds the characters used to draw a single event on a timeline.
+type timelineGlyphs struct {
+	start, fill, end, single rune
+	// unboundedStart and unboundedEnd mark the sides of an UnboundedEvent that extend past the edges of the timeline.
+	unboundedStart, unboundedEnd rune
+}
+
+var (
+	asciiGlyphs = timelineGlyphs{
+		start: '[', fill: '-', end: ')', single: '|', unboundedStart: '<', unboundedEnd: '>',
+	}
+	unicodeGlyphs = timelineGlyphs{
+		start: '├', fill: '─', end: '┤', single: '│', unboundedStart: '◂', unboundedEnd: '▸',
+	}
+)
+
+// Timeline renders the raw and merged schedules of the engine. See RenderTimeline for the format.
+func (e *Engine) Timeline(opts TimelineOptions) string {
+	return RenderTimeline(e.RawSchedule, e.MergedSchedule, opts)
+}
+
+// RenderTimeline draws the raw events stacked by desirability above the merged events, using the same notation as
+// the overlap diagrams in the comments of this package:
+//
+//	origin 2020-01-01T00:00:00Z, 1 column = 30m0s
+//	raw 1  |    [------)
+//	raw 0  |[------)
+//	merged |[--)[------)
+//
+// raw is expected to be sorted by desirability in ascending order (as Engine.RawSchedule is), so the most desirable
+// event is drawn on the top lane. Each column covers Scale of time; events shorter than a column still occupy one.
+// The missing sides of an UnboundedEvent extend to the edges of the timeline and are drawn as < and >.
+func RenderTimeline(raw, merged []Event, opts TimelineOptions) string {
+	if len(raw) == 0 && len(merged) == 0 {
+		return ""
+	}
+
+	glyphs := asciiGlyphs
+	if opts.Unicode {
+		glyphs = unicodeGlyphs
+	}
+
+	origin, end := timelineBounds(raw, merged)
+	scale := opts.Scale
+	if scale <= 0 {
+		width := opts.Width
+		if width <= 0 {
+			width = defaultTimelineWidth
+		}
+		scale = ceilDiv(end.Sub(origin), time.Duration(width))
+		if scale <= 0 {
+			scale = 1
+		}
+	}
+	columns := int(ceilDiv(end.Sub(origin), scale))
+	if columns < 1 {
+		columns = 1
+	}
+
+	// The missing sides of UnboundedEvent(s) get an extra column past the edges of the bounded events.
+	var unboundedStarts, unboundedEnds bool
+	for _, events := range [][]Event{raw, merged} {
+		for _, event := range events {
+			unboundedStarts = unboundedStarts || isUnbounded(startOf(event))
+			unboundedEnds = unboundedEnds || isUnbounded(endOf(event))
+		}
+	}
+	if unboundedStarts {
+		origin = origin.Add(-scale)
+		columns++
+	}
+	if unboundedEnds {
+		columns++
+	}
+
+	labels := make([]string, 0, len(raw)+1)
+	rows := make([][]rune, 0, len(raw)+1)
+	for i := len(raw) - 1; i >= 0; i-- {
+		labels = append(labels, fmt.Sprintf("raw %d", i))
+		rows = append(rows, drawTimelineRow(raw[i:i+1], origin, scale, columns, glyphs))
+	}
+	labels = append(labels, "merged")
+	rows = append(rows, drawTimelineRow(merged, origin, scale, columns, glyphs))
+
+	labelWidth := 0
+	for _, label := range labels {
+		if len(label) > labelWidth {
+			labelWidth = len(label)
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "origin %s, 1 column = %s\n", origin.Format(time.RFC3339), scale)
+	for i, row := range rows {
+		line := fmt.Sprintf("%-*s |%s", labelWidth, labels[i], string(row))
+		sb.WriteString(strings.TrimRight(line, " "))
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
+
+// drawTimelineRow draws events onto a single row of the given number of columns. Events drawn later overwrite the
+// columns they share with events drawn earlier.
+func drawTimelineRow(events []Event, origin time.Time, scale time.Duration, columns int, glyphs timelineGlyphs) []rune {
+	row := []rune(strings.Repeat(" ", columns))
+	for _, event := range events {
+		var (
+			start, end   = startOf(event), endOf(event)
+			first, last  = 0, columns - 1
+			startGlyph   = glyphs.unboundedStart
+			endGlyph     = glyphs.unboundedEnd
+			startBounded = !isUnbounded(start)
+			endBounded   = !isUnbounded(end)
+		)
+		if startBounded {
+			first = int(start.Sub(origin) / scale)
+			startGlyph = glyphs.start
+		}
+		if endBounded {
+			last = int(ceilDiv(end.Sub(origin), scale)) - 1
+			endGlyph = glyphs.end
+		}
+		first, last = min(first, columns-1), max(min(last, columns-1), first)
+		if first == last && startBounded && endBounded {
+			row[first] = glyphs.single
+			continue
+		}
+
+		for column := first + 1; column < last; column++ {
+			row[column] = glyphs.fill
+		}
+		row[first] = startGlyph
+		row[last] = endGlyph
+	}
+
+	return row
+}
+
+// timelineBounds returns the earliest and the latest time bounding any of the given events. The missing sides of
+// UnboundedEvent(s) are ignored.
+func timelineBounds(schedules ...[]Event) (start, end time.Time) {
+	var initialized bool
+	for _, events := range schedules {
+		for _, event := range events {
+			for _, bound := range []time.Time{startOf(event), endOf(event)} {
+				if isUnbounded(bound) {
+					continue
+				}
+				if !initialized || bound.Before(start) {
+					start = bound
+				}
+				if !initialized || bound.After(end) {
+					end = bound
+				}
+				initialized = true
+			}
+		}
+	}
+
+	return start, end
+}
+
+// ceilDiv divides d by m and rounds the result up. m must be positive.
+func ceilDiv(d, m time.Duration) time.Duration {
+	q := d / m
+	if d%m > 0 {
+		q++
+	}
+	return q
+}
@@ -0,0 +1,108 @@
+package scheduleMerge
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRenderTimeline(t *testing.T) {
+	tcs := []struct {
+		name     string
+		raw      schedule
+		trim     bool
+		opts     TimelineOptions
+		expected string
+	}{
+		{
+			name:     "empty",
+			expected: "",
+		},
+		{
+			// more desirable event:    [----)
+			// less desirable event: [----)
+			name: "2 events-[2.b]-trim",
+			raw: schedule{
+				{
+					StartTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+					EndTime:   time.Date(2020, 1, 1, 2, 0, 0, 0, time.UTC),
+					CreatedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+					ID:        1,
+				},
+				{
+					StartTime: time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC),
+					EndTime:   time.Date(2020, 1, 1, 3, 0, 0, 0, time.UTC),
+					CreatedAt: time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC),
+					ID:        2,
+				},
+			},
+			trim: true,
+			opts: TimelineOptions{Scale: 15 * time.Minute},
+			expected: "origin 2020-01-01T00:00:00Z, 1 column = 15m0s\n" +
+				"raw 1  |    [------)\n" +
+				"raw 0  |[------)\n" +
+				"merged |[--)[------)\n",
+		},
+		{
+			// more desirable event:  [--)
+			// less desirable event: [------)
+			name: "2 events-[3.c]-trim-unicode",
+			raw: schedule{
+				{
+					StartTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+					EndTime:   time.Date(2020, 1, 1, 3, 0, 0, 0, time.UTC),
+					CreatedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+					ID:        1,
+				},
+				{
+					StartTime: time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC),
+					EndTime:   time.Date(2020, 1, 1, 2, 0, 0, 0, time.UTC),
+					CreatedAt: time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC),
+					ID:        2,
+				},
+			},
+			trim: true,
+			opts: TimelineOptions{Scale: 30 * time.Minute, Unicode: true},
+			expected: "origin 2020-01-01T00:00:00Z, 1 column = 30m0s\n" +
+				"raw 1  |  ├┤\n" +
+				"raw 0  |├────┤\n" +
+				"merged |├┤├┤├┤\n",
+		},
+		{
+			// more desirable event:   [)
+			// less desirable event: [------)
+			name: "2 events-[3.c]-no trim-fit to width",
+			raw: schedule{
+				{
+					StartTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+					EndTime:   time.Date(2020, 1, 1, 8, 0, 0, 0, time.UTC),
+					CreatedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+					ID:        1,
+				},
+				{
+					StartTime: time.Date(2020, 1, 1, 4, 0, 0, 0, time.UTC),
+					EndTime:   time.Date(2020, 1, 1, 4, 10, 0, 0, time.UTC),
+					CreatedAt: time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC),
+					ID:        2,
+				},
+			},
+			trim: false,
+			opts: TimelineOptions{Width: 8},
+			expected: "origin 2020-01-01T00:00:00Z, 1 column = 1h0m0s\n" +
+				"raw 1  |    |\n" +
+				"raw 0  |[------)\n" +
+				"merged |    |\n",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			e := NewEngine(tc.raw, tc.trim)
+			e.Merge()
+
+			got := e.Timeline(tc.opts)
+			if got != tc.expected {
+				t.Fatalf("expected timeline:\n%s\ngot:\n%s", tc.expected, got)
+			}
+		})
+	}
+}
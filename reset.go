@@ -0,0 +1,42 @@
+package scheduleMerge
+
+// Reset replaces the raw schedule with rawSchedule, sorted like NewEngine sorts it, and throws away the result of
+// merging, so that the engine can be reused for another schedule, e.g. by a job that merges a new export every hour.
+// The settings of the engine (TrimOverlaps, Location, Constraints, CloneEvent, ...) are kept, but the Sources of the
+// layers of NewLayeredEngine are not.
+//
+// The engine reuses its buffers and the index of the parts of trimmed events rather than allocating them again, while
+// MergedSchedule, Conflicts and Violations taken from it before stay as they are. Reset forgets the changes Undo and
+// Redo could revert.
+func (e *Engine) Reset(rawSchedule Schedule) {
+	rawSchedule.SortByDesirability()
+	events := rawSchedule.GetEvents()
+	pinLast(events)
+
+	e.record(Op{Kind: OpReset})
+	e.reset()
+	e.RawSchedule = events
+	for rank, rawEvent := range events {
+		e.record(Op{Kind: OpInsert, Rank: rank, Event: rawEvent})
+	}
+}
+
+// Remerge merges the whole raw schedule again, e.g. after changing Constraints or CloneEvent, which do not affect a
+// merge that has already finished. Settings with a setter (SetTrimOverlaps and SetLocation) already make the next
+// Merge start from scratch. Like Reset, Remerge reuses the buffers of the engine.
+func (e *Engine) Remerge() {
+	e.resetMerge()
+	e.Merge()
+}
+
+// reset empties the raw schedule and the result of merging, and forgets the undo history, which shares the index of
+// the parts with the engine.
+func (e *Engine) reset() {
+	e.undo, e.redo = nil, nil
+	// The raw schedule belongs to the Schedule it was taken from, so it is not reused.
+	e.RawSchedule = nil
+	// The sources of the layers do not apply to the new raw schedule. They are shared with the Snapshots, so they are
+	// not cleared.
+	e.sources = nil
+	e.resetMerge()
+}
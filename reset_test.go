@@ -0,0 +1,177 @@
+package scheduleMerge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEngine_Reset(t *testing.T) {
+	for _, trimOverlaps := range []bool{true, false} {
+		e := NewEngine(randomSchedule(1, 50, 48), trimOverlaps)
+		e.UndoLimit = 10
+		e.Merge()
+		e.Add(&event{StartTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), EndTime: time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC)})
+
+		for seed := int64(2); seed <= 10; seed++ {
+			before := e.Snapshot()
+			e.Reset(randomSchedule(seed, 50, 48))
+			e.Merge()
+
+			expected := NewEngine(randomSchedule(seed, 50, 48), trimOverlaps)
+			expected.Merge()
+			if diff := cmp.Diff(mergedEvents(expected.MergedSchedule), mergedEvents(e.MergedSchedule)); diff != "" {
+				t.Fatalf("seed %d, trimOverlaps %v: unexpected merged schedule:\n%s", seed, trimOverlaps, diff)
+			}
+			if len(expected.Conflicts) != len(e.Conflicts) {
+				t.Fatalf("seed %d, trimOverlaps %v: expected %d conflicts, got %d", seed, trimOverlaps,
+					len(expected.Conflicts), len(e.Conflicts))
+			}
+			if err := CheckInvariants(before.MergedSchedule); err != nil {
+				t.Fatalf("expected the snapshot to be kept: %v", err)
+			}
+		}
+
+		if e.TrimOverlaps != trimOverlaps || e.UndoLimit != 10 {
+			t.Fatalf("expected the settings to be kept")
+		}
+		if e.Undo() {
+			t.Fatalf("expected Reset to forget the undo history")
+		}
+	}
+}
+
+func TestEngine_Reset_Journal(t *testing.T) {
+	e := NewEngine(randomSchedule(1, 20, 48), true)
+	journal := e.StartJournal()
+	e.Merge()
+	e.Reset(randomSchedule(2, 20, 48))
+	e.Add(&event{StartTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), EndTime: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)})
+	e.Merge()
+
+	replayed, err := journal.Replay(len(journal.Ops))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(mergedEvents(e.MergedSchedule), mergedEvents(replayed.MergedSchedule)); diff != "" {
+		t.Fatalf("unexpected replayed merged schedule:\n%s", diff)
+	}
+}
+
+func TestEngine_Remerge(t *testing.T) {
+	origin := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := NewEngine(schedule{{StartTime: origin, EndTime: origin.Add(12 * time.Hour), ID: 1}}, true)
+	e.Merge()
+
+	e.Constraints = []Constraint{MaxDailyDuration{Limit: 8 * time.Hour}}
+	e.Merge()
+	if len(e.Violations) != 0 {
+		t.Fatalf("expected Merge not to merge again")
+	}
+
+	e.Remerge()
+	expected := []event{{StartTime: origin, EndTime: origin.Add(8 * time.Hour), ID: 1}}
+	if diff := cmp.Diff(expected, mergedEvents(e.MergedSchedule)); diff != "" {
+		t.Fatalf("unexpected merged schedule:\n%s", diff)
+	}
+
+	// Remerge reuses the buffers of the engine like Reset.
+	raw := orderedSchedule(randomSchedule(1, 200, 48).GetEvents())
+	parts := make([]event, 0, 1000)
+	e = NewEngine(raw, true)
+	e.CloneEvent = func(e Event) Event {
+		parts = append(parts, *(e.(*event)))
+		return &parts[len(parts)-1]
+	}
+	e.Merge()
+	allocs := testing.AllocsPerRun(10, func() {
+		parts = parts[:0]
+		e.Remerge()
+	})
+	// The new merged schedule and conflicts, which leave the ones taken from the engine before as they are.
+	if allocs > 2 {
+		t.Fatalf("expected Remerge to reuse the memory of the engine, got %v allocations", allocs)
+	}
+}
+
+func TestEngine_Reset_Sources(t *testing.T) {
+	origin := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	shift := &event{StartTime: origin, EndTime: origin.Add(8 * time.Hour), ID: 1}
+	e := NewLayeredEngine([]Layer{{Source: "roster", Schedule: schedule{shift}}}, true)
+	e.Merge()
+
+	e.Reset(schedule{shift})
+	e.Merge()
+	if source, ok := e.Source(shift); ok {
+		t.Fatalf("expected Reset to forget the sources of the layers, got %q", source)
+	}
+}
+
+func TestEngine_Reset_Allocs(t *testing.T) {
+	raw := orderedSchedule(randomSchedule(1, 200, 48).GetEvents())
+	parts := make([]event, 0, 1000)
+	clone := func(e Event) Event {
+		parts = append(parts, *(e.(*event)))
+		return &parts[len(parts)-1]
+	}
+
+	fresh := testing.AllocsPerRun(10, func() {
+		parts = parts[:0]
+		e := NewEngine(raw, true)
+		e.CloneEvent = clone
+		e.Merge()
+	})
+
+	e := NewEngine(raw, true)
+	e.CloneEvent = clone
+	e.Merge()
+	reused := testing.AllocsPerRun(10, func() {
+		parts = parts[:0]
+		e.Reset(raw)
+		e.Merge()
+	})
+	// Sorting the raw schedule, and the new merged schedule and conflicts, which leave the ones taken from the engine
+	// before as they are.
+	if reused > 3 || reused >= fresh {
+		t.Fatalf("expected Reset to reuse the memory of the engine, got %v allocations (%v for a new engine)", reused, fresh)
+	}
+}
+
+func TestEngine_Reset_KeepsResults(t *testing.T) {
+	for _, name := range []string{"reset", "remerge"} {
+		t.Run(name, func(t *testing.T) {
+			e := NewEngine(randomSchedule(1, 50, 48), true)
+			e.Constraints = []Constraint{MaxDailyDuration{Limit: 10 * time.Hour}}
+			e.Merge()
+			mergedSchedule, conflicts, violations := e.MergedSchedule, e.Conflicts, e.Violations
+			expected := e.Snapshot()
+			if len(conflicts) == 0 || len(violations) == 0 {
+				t.Fatalf("expected conflicts and violations, got %d and %d", len(conflicts), len(violations))
+			}
+			expectedViolations := append([]Violation(nil), violations...)
+
+			if name == "reset" {
+				e.Reset(randomSchedule(2, 50, 48))
+				e.Merge()
+			} else {
+				e.Constraints = []Constraint{MaxDailyDuration{Limit: 6 * time.Hour}}
+				e.Remerge()
+			}
+
+			if diff := cmp.Diff(mergedEvents(expected.MergedSchedule), mergedEvents(mergedSchedule)); diff != "" {
+				t.Fatalf("expected the merged schedule to stay as it is:\n%s", diff)
+			}
+			for i, conflict := range conflicts {
+				if conflict != expected.Conflicts[i] {
+					t.Fatalf("expected conflict %d to stay as it is, got %+v", i, conflict)
+				}
+			}
+			for i, violation := range violations {
+				if violation.Message != expectedViolations[i].Message || violation.Cut != expectedViolations[i].Cut {
+					t.Fatalf("expected violation %d to stay as it is, got %+v", i, violation)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package scheduleMerge
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxDailyDuration limits the total duration of the events on every day. Events without a start or without an end
+// (see UnboundedEvent) are ignored.
+type MaxDailyDuration struct {
+	// Limit is the maximum total duration of the events on a day.
+	Limit time.Duration
+	// The time zone the days start in. Defaults to UTC if nil.
+	Location *time.Location
+}
+
+// Check returns a violation for every day on which the events last longer than the limit. It proposes to cut the
+// excess from the end of the part of every event on that day.
+func (c MaxDailyDuration) Check(mergedSchedule []Event) []Violation {
+	loc := c.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	type day struct {
+		start, end time.Time
+		total      time.Duration
+		parts      []Cut
+	}
+	var days []*day
+	byDate := map[Date]*day{}
+	for _, event := range mergedSchedule {
+		start, end := startOf(event), endOf(event)
+		if isUnbounded(start) || isUnbounded(end) {
+			continue
+		}
+
+		for date := DateOf(start.In(loc)); date.In(loc).Before(end); date = date.AddDays(1) {
+			d, ok := byDate[date]
+			if !ok {
+				d = &day{start: date.In(loc), end: date.AddDays(1).In(loc)}
+				byDate[date] = d
+				days = append(days, d)
+			}
+
+			part := Cut{Event: event, Start: start, End: end}
+			if part.Start.Before(d.start) {
+				part.Start = d.start
+			}
+			if part.End.After(d.end) {
+				part.End = d.end
+			}
+			if part.Start.Before(part.End) {
+				d.total += part.End.Sub(part.Start)
+				d.parts = append(d.parts, part)
+			}
+		}
+	}
+
+	var violations []Violation
+	for _, d := range days {
+		excess := d.total - c.Limit
+		if excess <= 0 {
+			continue
+		}
+
+		v := Violation{
+			Start:   d.start,
+			End:     d.end,
+			Message: fmt.Sprintf("%v of events on %v exceed the daily maximum of %v", d.total, d.start.Format(time.DateOnly), c.Limit),
+		}
+		for _, part := range d.parts {
+			if length := part.End.Sub(part.Start); length > excess {
+				part.Start = part.End.Add(-excess)
+			}
+			v.Cuts = append(v.Cuts, part)
+		}
+		violations = append(violations, v)
+	}
+	return violations
+}
+
+// MinRest requires a minimum rest between two events. Gaps up to MaxBreak are breaks rather than rests, e.g. a lunch
+// break between two shifts of the same day, and are not checked. Events without a start or without an end (see
+// UnboundedEvent) are ignored.
+type MinRest struct {
+	// Rest is the minimum duration of a rest.
+	Rest time.Duration
+	// MaxBreak is the maximum duration of a break.
+	MaxBreak time.Duration
+}
+
+// Check returns a violation for every gap between two events that is longer than MaxBreak and shorter than Rest. It
+// proposes to cut the missing rest from the end of the earlier or from the start of the later event.
+func (c MinRest) Check(mergedSchedule []Event) []Violation {
+	var (
+		violations []Violation
+		previous   Event
+	)
+	for _, event := range mergedSchedule {
+		start, end := startOf(event), endOf(event)
+		if isUnbounded(start) || isUnbounded(end) {
+			continue
+		}
+		if previous == nil {
+			previous = event
+			continue
+		}
+
+		previousEnd := endOf(previous)
+		if gap := start.Sub(previousEnd); gap > c.MaxBreak && gap < c.Rest {
+			missing := c.Rest - gap
+			violations = append(violations, Violation{
+				Start:   previousEnd,
+				End:     start,
+				Message: fmt.Sprintf("rest of %v is shorter than the minimum of %v", gap, c.Rest),
+				Cuts: []Cut{
+					{Event: previous, Start: previousEnd.Add(-missing), End: previousEnd},
+					{Event: event, Start: start, End: start.Add(missing)},
+				},
+			})
+		}
+		previous = event
+	}
+	return violations
+}
+
+// MaxConsecutive limits the duration of a run of events without a break of at least MinBreak in between. Events
+// without a start or without an end (see UnboundedEvent) are ignored.
+type MaxConsecutive struct {
+	// Limit is the maximum duration of a run, from the start of its first to the end of its last event.
+	Limit time.Duration
+	// MinBreak is the minimum duration of a gap that ends a run.
+	MinBreak time.Duration
+}
+
+// Check returns a violation for every run that lasts longer than the limit. It proposes to cut a break of MinBreak
+// out of the events right where the run reaches the limit.
+func (c MaxConsecutive) Check(mergedSchedule []Event) []Violation {
+	var (
+		violations []Violation
+		run        []Event
+		start, end time.Time // The bounds of the run.
+	)
+	closeRun := func() {
+		if len(run) == 0 || end.Sub(start) <= c.Limit {
+			return
+		}
+
+		breakStart := start.Add(c.Limit)
+		breakEnd := breakStart.Add(c.MinBreak)
+		if c.MinBreak <= 0 {
+			breakEnd = end
+		}
+		v := Violation{
+			Start:   start,
+			End:     end,
+			Message: fmt.Sprintf("%v without a break exceed the maximum of %v", end.Sub(start), c.Limit),
+		}
+		for _, event := range run {
+			if startOf(event).Before(breakEnd) && endOf(event).After(breakStart) {
+				v.Cuts = append(v.Cuts, Cut{Event: event, Start: breakStart, End: breakEnd})
+			}
+		}
+		violations = append(violations, v)
+	}
+
+	for _, event := range mergedSchedule {
+		eventStart, eventEnd := startOf(event), endOf(event)
+		if isUnbounded(eventStart) || isUnbounded(eventEnd) {
+			continue
+		}
+
+		if gap := eventStart.Sub(end); len(run) > 0 && gap > 0 && gap >= c.MinBreak {
+			closeRun()
+			run = run[:0]
+		}
+		if len(run) == 0 {
+			start = eventStart
+		}
+		run = append(run, event)
+		if eventEnd.After(end) || len(run) == 1 {
+			end = eventEnd
+		}
+	}
+	closeRun()
+	return violations
+}
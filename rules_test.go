@@ -0,0 +1,134 @@
+package scheduleMerge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestConstraint_Check(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// cut describes a proposed Cut by the ID of its event.
+	type cut struct {
+		ID         int
+		Start, End time.Time
+	}
+	// violation describes a Violation by its span and its proposed cuts.
+	type violation struct {
+		Start, End time.Time
+		Cuts       []cut
+	}
+
+	tests := []struct {
+		name       string
+		constraint Constraint
+		merged     []*event
+		expected   []violation
+	}{
+		{
+			name:       "max daily duration-satisfied",
+			constraint: MaxDailyDuration{Limit: 8 * time.Hour},
+			merged:     []*event{{StartTime: at(8), EndTime: at(12), ID: 1}, {StartTime: at(13), EndTime: at(17), ID: 2}},
+		},
+		{
+			name:       "max daily duration-exceeded",
+			constraint: MaxDailyDuration{Limit: 8 * time.Hour},
+			merged:     []*event{{StartTime: at(8), EndTime: at(12), ID: 1}, {StartTime: at(13), EndTime: at(18), ID: 2}},
+			expected: []violation{{
+				Start: at(0),
+				End:   at(24),
+				Cuts:  []cut{{1, at(11), at(12)}, {2, at(17), at(18)}},
+			}},
+		},
+		{
+			name:       "max daily duration-event across midnight",
+			constraint: MaxDailyDuration{Limit: 4 * time.Hour},
+			merged:     []*event{{StartTime: at(18), EndTime: at(30), ID: 1}},
+			expected: []violation{
+				{Start: at(0), End: at(24), Cuts: []cut{{1, at(22), at(24)}}},
+				{Start: at(24), End: at(48), Cuts: []cut{{1, at(28), at(30)}}},
+			},
+		},
+		{
+			name:       "max daily duration-location",
+			constraint: MaxDailyDuration{Limit: 4 * time.Hour, Location: berlin},
+			merged:     []*event{{StartTime: at(20), EndTime: at(28), ID: 1}},
+			expected: []violation{
+				{Start: at(23), End: at(47), Cuts: []cut{{1, at(27), at(28)}}},
+			},
+		},
+		{
+			name:       "min rest-break",
+			constraint: MinRest{Rest: 11 * time.Hour, MaxBreak: time.Hour},
+			merged:     []*event{{StartTime: at(8), EndTime: at(12), ID: 1}, {StartTime: at(13), EndTime: at(17), ID: 2}},
+		},
+		{
+			name:       "min rest-rest",
+			constraint: MinRest{Rest: 11 * time.Hour, MaxBreak: time.Hour},
+			merged:     []*event{{StartTime: at(8), EndTime: at(17), ID: 1}, {StartTime: at(28), EndTime: at(36), ID: 2}},
+		},
+		{
+			name:       "min rest-too short",
+			constraint: MinRest{Rest: 11 * time.Hour, MaxBreak: time.Hour},
+			merged:     []*event{{StartTime: at(8), EndTime: at(17), ID: 1}, {StartTime: at(25), EndTime: at(33), ID: 2}},
+			expected: []violation{{
+				Start: at(17),
+				End:   at(25),
+				Cuts:  []cut{{1, at(14), at(17)}, {2, at(25), at(28)}},
+			}},
+		},
+		{
+			name:       "max consecutive-satisfied",
+			constraint: MaxConsecutive{Limit: 6 * time.Hour, MinBreak: time.Hour},
+			merged:     []*event{{StartTime: at(8), EndTime: at(12), ID: 1}, {StartTime: at(13), EndTime: at(17), ID: 2}},
+		},
+		{
+			name:       "max consecutive-exceeded",
+			constraint: MaxConsecutive{Limit: 6 * time.Hour, MinBreak: 2 * time.Hour},
+			merged: []*event{
+				{StartTime: at(8), EndTime: at(12), ID: 1},
+				{StartTime: at(13), EndTime: at(17), ID: 2},
+				{StartTime: at(20), EndTime: at(21), ID: 3},
+			},
+			expected: []violation{{
+				Start: at(8),
+				End:   at(17),
+				Cuts:  []cut{{2, at(14), at(16)}},
+			}},
+		},
+		{
+			name:       "max consecutive-adjacent events",
+			constraint: MaxConsecutive{Limit: 6 * time.Hour, MinBreak: time.Hour},
+			merged:     []*event{{StartTime: at(8), EndTime: at(14), ID: 1}, {StartTime: at(14), EndTime: at(17), ID: 2}},
+			expected: []violation{{
+				Start: at(8),
+				End:   at(17),
+				Cuts:  []cut{{2, at(14), at(15)}},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged := schedule(tt.merged).GetEvents()
+
+			var got []violation
+			for _, v := range tt.constraint.Check(merged) {
+				if v.Message == "" {
+					t.Errorf("expected a message")
+				}
+				gotViolation := violation{Start: v.Start, End: v.End}
+				for _, c := range v.Cuts {
+					gotViolation.Cuts = append(gotViolation.Cuts, cut{ID: c.Event.(*event).ID, Start: c.Start, End: c.End})
+				}
+				got = append(got, gotViolation)
+			}
+			if diff := cmp.Diff(tt.expected, got, cmp.Comparer(time.Time.Equal)); diff != "" {
+				t.Errorf("unexpected violations:\n%s", diff)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package scheduleMerge
 
 import (
+	"context"
 	"time"
 )
 
@@ -32,8 +33,10 @@ type Schedule interface {
 
 func NewEngine(rawSchedule Schedule, trimOverlaps bool) *Engine {
 	rawSchedule.SortByDesirability()
+	events := rawSchedule.GetEvents()
+	pinLast(events)
 	return &Engine{
-		RawSchedule:    rawSchedule.GetEvents(),
+		RawSchedule:    events,
 		MergedSchedule: []Event{},
 		TrimOverlaps:   trimOverlaps,
 	}
@@ -41,77 +44,192 @@ func NewEngine(rawSchedule Schedule, trimOverlaps bool) *Engine {
 
 // Engine is the main struct that is used to merge the potentially conflicting raw events into a single conflict-free
 // schedule.
+//
+// The engine keeps track of which raw event every trimmed part originates from. Events are told apart with ==, except
+// for Event types that cannot be compared: maps and slices are told apart by the data they refer to, and the parts of
+// any other such Event (e.g. a struct holding a slice) are merged like all the others, but not traced back to their
+// raw events.
 type Engine struct {
-	// The raw schedule passed to the engine via the NewEngine constructor.
+	// The raw schedule passed to the engine via the NewEngine constructor, sorted by desirability in ascending order.
+	// Pinned events (see PolicyPinned) come after all the other events.
 	RawSchedule []Event
-	// The merged schedule that is created by the engine.
+	// The merged schedule that is created by the engine. Merging replaces it rather than changing it, so a merged
+	// schedule taken from the engine stays as it is.
 	MergedSchedule []Event
 	// Indicates whether the engine should trim the overlaps between the events. If true, the engine will trim the
-	// overlaps between the events. If false, the engine will discard the less desirable conflicting event.
+	// overlaps between the events. If false, the engine will discard the less desirable conflicting event. Events can
+	// override it with their own Policy (see PolicyEvent).
 	TrimOverlaps bool
+	// The time zone the dates of AllDayEvent(s) are resolved in. Defaults to UTC if nil.
+	Location *time.Location
+	// The conflicts that were resolved while creating the merged schedule, in the order they were resolved.
+	Conflicts []Conflict
+	// Called by MergeContext (and Merge) with the number of raw events processed so far out of the total number of
+	// raw events. It is optional.
+	OnProgress func(processed, total int)
+	// The number of changes Undo can revert. Zero disables Undo and Redo.
+	UndoLimit int
+	// The rules the merged schedule has to satisfy. Once the raw schedule is merged, the violations of the
+	// constraints are resolved by cutting time from the least desirable events (see Constraint). Every Merge enforces
+	// them anew, so time that was cut is given back once a later Add no longer needs it to be cut.
+	Constraints []Constraint
+	// The violations of the Constraints that were resolved (or could not be resolved) after the last merge, in the
+	// order they were resolved.
+	Violations []Violation
+	// Called instead of Event.Clone to create the parts of trimmed events, e.g. to take them from an arena or a
+	// sync.Pool rather than allocating every part on its own. The engine never hands parts back, so pooled parts can
+	// only be returned once the merged schedule is no longer used. MergeParallel calls it from several goroutines. It is
+	// optional.
+	CloneEvent func(Event) Event
+	// The bounds of the parts of trimmed events are rounded inward to multiples of Granularity (e.g. 15 minutes) once
+	// the raw schedule is merged. The bounds of the raw events themselves are kept, and so are the Conflicts. Zero
+	// disables the rounding.
+	Granularity time.Duration
+	// The parts of trimmed events shorter than MinFragment are dropped from the merged schedule once the raw schedule
+	// is merged, e.g. to get rid of five minutes left of a meeting. Zero keeps all the parts.
+	MinFragment time.Duration
+	// Notified while the engine merges (see Observer). They are optional.
+	Observers []Observer
 
 	mergingFinished bool
+	// processed is the number of raw events that have been merged into the merged schedule.
+	processed int
+	// merged is the result of merging the processed raw events, before the parts are refined and the Constraints are
+	// enforced. Merging changes it in place; MergedSchedule is a copy of it (see publish).
+	merged []Event
+	// origins maps the parts created by trimming an event to the raw event they originate from.
+	origins eventMap[Event]
+	// sources maps the raw events to the Source of their Layer, if the engine was created by NewLayeredEngine.
+	sources eventMap[string]
+	// journal records the changes of the engine, if StartJournal was called.
+	journal *Journal
+	// undo and redo hold the states Undo and Redo restore, the latest one last.
+	undo, redo []engineState
+	// buffer holds the merged events built by merge. It is reused for every raw event, so that merging a raw event
+	// does not allocate a new slice.
+	buffer []Event
 }
 
+// progressInterval is the number of raw events MergeContext merges between two checks of its context and two reports
+// of its progress.
+const progressInterval = 256
+
+// Merge merges the raw schedule into the merged schedule. Calling Merge again after it has finished is a no-op.
 func (e *Engine) Merge() {
+	// MergeContext only fails if its context is done, which never happens to context.Background.
+	_ = e.MergeContext(context.Background())
+}
+
+// MergeContext is like Merge, but it stops early and returns ctx.Err() if ctx is done. The context is checked
+// every few hundred raw events, at the same points at which the progress is reported to OnProgress.
+//
+// If MergeContext stops early, MergedSchedule (and Conflicts) hold the result of merging the least desirable raw
+// events that have been processed so far. Calling Merge or MergeContext again continues where it stopped.
+func (e *Engine) MergeContext(ctx context.Context) error {
 	if e.mergingFinished {
-		return
-	}
-	if len(e.RawSchedule) == 0 {
-		return
+		return nil
 	}
 
 	// Incoming rawEvents are sorted by Desirability from the least desirable to the
-	// most desirable. Events in `e.MergedSchedule` are sorted by StartTime/EndTime from
+	// most desirable. Events in `e.merged` are sorted by StartTime/EndTime from
 	// oldest to newest and never overlap with each other.
-	for _, rawEvent := range e.RawSchedule {
-		if len(e.MergedSchedule) == 0 {
-			e.MergedSchedule = append(e.MergedSchedule, rawEvent)
-			continue
-		}
-
-		// At least one event has already been inserted into the `e.MergedSchedule`.
-		// Find all events in `e.MergedSchedule` that are completely before the rawEvent. We can safely insert the
-		// rawEvent after the last event that is completely before the rawEvent.
-		//
-		// rawEvent (more desirable):       [----)
-		// PCME(s) (less desirable) : [----)
-		lastSafeMergedEventIndex := findLastSafeMergedEventIndex(rawEvent, e.MergedSchedule)
-
-		// We will isolate all the events in `e.MergedSchedule` that are potentially conflicting with the rawEvent and
-		// check in detail.
-		safeMergedEvents, potentialConflictMergedEvents := splitMergedEventsOnSafeInsert(lastSafeMergedEventIndex, e.MergedSchedule)
-
-		if len(potentialConflictMergedEvents) == 0 {
-			// There are no events in `e.MergedSchedule` that are potentially conflicting with the rawEvent.
-			// Therefore, we can safely insert the rawEvent after the last event that is completely before the rawEvent.
-			e.MergedSchedule = append(safeMergedEvents, rawEvent)
-			continue
+	total := len(e.RawSchedule)
+	for e.processed < total {
+		if e.processed%progressInterval == 0 {
+			e.reportProgress(total)
+			if err := ctx.Err(); err != nil {
+				e.publish()
+				return err
+			}
 		}
 
-		// There are events in `e.MergedSchedule` that are potentially conflicting with the rawEvent. We will check
-		// each of them in detail.
-		mergedSchedule := e.merge(rawEvent, potentialConflictMergedEvents)
-		e.MergedSchedule = append(safeMergedEvents, mergedSchedule...)
+		rawEvent := e.RawSchedule[e.processed]
+		e.resolve(rawEvent)
+		e.mergeRawEvent(rawEvent)
+		e.processed++
 	}
 
+	e.publish()
+	e.refineAndEnforce()
+	e.reportProgress(total)
 	e.mergingFinished = true
+	return nil
+}
+
+// publish sets MergedSchedule to a copy of the merged raw events. Merging changes them in place, and the parts are
+// refined and the Constraints are enforced on the copy only, so that the next merge starts from the raw events as they
+// were merged, rather than from the time the constraints cut. The violations are found again on the copy.
+func (e *Engine) publish() {
+	e.MergedSchedule = append(make([]Event, 0, len(e.merged)), e.merged...)
+	e.Violations = make([]Violation, 0, cap(e.Violations))
+}
+
+// reportProgress passes the number of processed raw events to OnProgress, if set, and to the Observers.
+func (e *Engine) reportProgress(total int) {
+	if e.OnProgress != nil {
+		e.OnProgress(e.processed, total)
+	}
+	e.notifyProgress(total)
+}
+
+// mergeRawEvent merges a single rawEvent, which is more desirable than all the events merged before it, into the
+// merged schedule.
+func (e *Engine) mergeRawEvent(rawEvent Event) {
+	if len(e.merged) == 0 {
+		e.merged = append(e.merged, rawEvent)
+		return
+	}
+
+	// At least one event has already been inserted into the `e.merged`.
+	// Find all events in `e.merged` that are completely before the rawEvent. We can safely insert the
+	// rawEvent after the last event that is completely before the rawEvent.
+	//
+	// rawEvent (more desirable):       [----)
+	// PCME(s) (less desirable) : [----)
+	lastSafeMergedEventIndex := findLastSafeMergedEventIndex(rawEvent, e.merged)
+
+	// We will isolate all the events in `e.merged` that are potentially conflicting with the rawEvent and
+	// check in detail.
+	safeMergedEvents, potentialConflictMergedEvents := splitMergedEventsOnSafeInsert(lastSafeMergedEventIndex, e.merged)
+
+	if len(potentialConflictMergedEvents) == 0 {
+		// There are no events in `e.merged` that are potentially conflicting with the rawEvent.
+		// Therefore, we can safely insert the rawEvent after the last event that is completely before the rawEvent.
+		e.merged = append(safeMergedEvents, rawEvent)
+		return
+	}
+
+	// There are events in `e.merged` that are potentially conflicting with the rawEvent. We will check
+	// each of them in detail.
+	mergedSchedule := e.merge(rawEvent, potentialConflictMergedEvents)
+	e.merged = append(safeMergedEvents, mergedSchedule...)
+	// The buffer is cleared so that it does not keep the events alive once they leave the merged schedule.
+	clear(mergedSchedule)
+	e.buffer = mergedSchedule[:0]
+}
+
+// resolve turns the wall clock readings and the dates of the rawEvent into instants before it is merged.
+func (e *Engine) resolve(rawEvent Event) {
+	resolveWallClock(rawEvent)
+	resolveAllDay(rawEvent, e.Location)
 }
 
 func (e *Engine) merge(rawEvent Event, PCMEs []Event) (mergedSchedule []Event) {
 	var (
-		rawStart         = rawEvent.GetStartTime()
-		rawEnd           = rawEvent.GetEndTime()
+		rawStart         = startOf(rawEvent)
+		rawEnd           = endOf(rawEvent)
 		rawInserted      bool // Indicates whether the rawEvent has been inserted into the mergedSchedule.
 		rawInsertedIndex int  // Indicates the index of the rawEvent in the mergedSchedule.
 	)
+	mergedSchedule = e.buffer[:0]
 
 	// PCME(s) (Potentially Conflicting Merged Event(s)) are sorted by StartTime from oldest to newest and never
 	// overlap with each other.
 	for PCMEIndex, PCME := range PCMEs {
 		var (
-			pcmeStart = PCME.GetStartTime()
-			pcmeEnd   = PCME.GetEndTime()
+			pcmeStart = startOf(PCME)
+			pcmeEnd   = endOf(PCME)
+			overlap   = classifyOverlap(rawStart, rawEnd, pcmeStart, pcmeEnd)
 		)
 
 		// Check for all types of (non)overlaps between the rawEvent and the current PCME.
@@ -148,8 +266,9 @@ func (e *Engine) merge(rawEvent Event, PCMEs []Event) (mergedSchedule []Event) {
 
 		// "2.b": rawEvent (more desirable):    [----)
 		//        PCME (less desirable)    : [----)
-		if rawStart.After(pcmeStart) && rawStart.Before(pcmeEnd) && rawEnd.After(pcmeEnd) {
-			if !e.TrimOverlaps {
+		if overlap == OverlapPartialEnd {
+			e.recordConflict(rawEvent, PCME, rawStart, pcmeEnd)
+			if !e.trims(PCME) {
 				// If we are not trimming overlaps, we can safely ignore the current PCME and move on.
 				if !rawInserted {
 					mergedSchedule = append(mergedSchedule, rawEvent)
@@ -159,9 +278,10 @@ func (e *Engine) merge(rawEvent Event, PCMEs []Event) (mergedSchedule []Event) {
 				continue
 			}
 
-			// If we are trimming overlaps, we can trim the current PCME and insert the rawEvent after it.
-			pcmePart := PCME.Clone()
-			pcmePart.SetEndTime(rawStart)
+			// If we are trimming overlaps, we can trim the current PCME and insert the rawEvent after it. The new
+			// bounds of trimmed parts are kept in the time zone of the bounds they replace.
+			pcmePart := e.fragment(PCME)
+			pcmePart.SetEndTime(rawStart.In(boundLocation(pcmeEnd, pcmeStart)))
 
 			// if rawInserted {
 			//     // Because we are processing the PCMEs in time order, we can safely assume that the rawEvent
@@ -188,9 +308,10 @@ func (e *Engine) merge(rawEvent Event, PCMEs []Event) (mergedSchedule []Event) {
 		//
 		// "3.e": rawEvent (more desirable): [----------)
 		//        PCME (less desirable)    :       [----)
-		if (pcmeStart.After(rawStart) || pcmeStart.Equal(rawStart)) &&
-			(pcmeEnd.Before(rawEnd) || pcmeEnd.Equal(rawEnd)) {
+		if overlap == OverlapEqual || overlap == OverlapContains || overlap == OverlapContainsStart ||
+			overlap == OverlapContainsEnd {
 			// If so, insert the rawEvent and ignore the current PCME.
+			e.recordConflict(rawEvent, PCME, pcmeStart, pcmeEnd)
 			if !rawInserted {
 				mergedSchedule = append(mergedSchedule, rawEvent)
 				rawInsertedIndex = PCMEIndex
@@ -202,13 +323,15 @@ func (e *Engine) merge(rawEvent Event, PCMEs []Event) (mergedSchedule []Event) {
 		// Check if the rawEvent is fully contained by the current mergedEvent.
 		// "3.c": rawEvent (more desirable):    [----)
 		//        PCME (less desirable)    : [----------)
-		if (rawStart.After(pcmeStart) || rawStart.Equal(pcmeStart)) &&
-			(rawEnd.Before(pcmeEnd) || rawEnd.Equal(pcmeEnd)) {
-			if !e.TrimOverlaps {
-				// If we are not trimming overlaps, we can safely ignore the current PCME and move on.
+		if overlap == OverlapWithin {
+			e.recordConflict(rawEvent, PCME, rawStart, rawEnd)
+			if !e.trims(PCME) {
+				// If we are not trimming overlaps, we can safely ignore the current PCME. The PCMEs after it start
+				// after the rawEvent ends, so they are kept.
 				if !rawInserted {
 					mergedSchedule = append(mergedSchedule, rawEvent)
 				}
+				mergedSchedule = append(mergedSchedule, PCMEs[PCMEIndex+1:]...)
 				break
 			}
 
@@ -219,12 +342,12 @@ func (e *Engine) merge(rawEvent Event, PCMEs []Event) (mergedSchedule []Event) {
 				pcmePart2 Event
 			)
 			if !rawStart.Equal(pcmeStart) {
-				pcmePart1 = PCME.Clone()
-				pcmePart1.SetEndTime(rawStart)
+				pcmePart1 = e.fragment(PCME)
+				pcmePart1.SetEndTime(rawStart.In(boundLocation(pcmeEnd, pcmeStart)))
 			}
 			if !rawEnd.Equal(pcmeEnd) {
-				pcmePart2 = PCME.Clone()
-				pcmePart2.SetStartTime(rawEnd)
+				pcmePart2 = e.fragment(PCME)
+				pcmePart2.SetStartTime(rawEnd.In(boundLocation(pcmeStart, pcmeEnd)))
 			}
 
 			if !rawInserted {
@@ -270,8 +393,9 @@ func (e *Engine) merge(rawEvent Event, PCMEs []Event) (mergedSchedule []Event) {
 
 		// "2.a": rawEvent (more desirable): [----)
 		//        PCME (less desirable)    :    [----)
-		if rawStart.Before(pcmeStart) && rawEnd.After(pcmeStart) && rawEnd.Before(pcmeEnd) {
-			if !e.TrimOverlaps {
+		if overlap == OverlapPartialStart {
+			e.recordConflict(rawEvent, PCME, pcmeStart, rawEnd)
+			if !e.trims(PCME) {
 				// If we are not trimming overlaps, we can safely ignore the current PCME and move on.
 				if !rawInserted {
 					mergedSchedule = append(mergedSchedule, rawEvent)
@@ -282,8 +406,8 @@ func (e *Engine) merge(rawEvent Event, PCMEs []Event) (mergedSchedule []Event) {
 			}
 
 			// If we are trimming overlaps, we can trim the current PCME and insert the rawEvent before it.
-			pcmePart := PCME.Clone()
-			pcmePart.SetStartTime(rawEnd)
+			pcmePart := e.fragment(PCME)
+			pcmePart.SetStartTime(rawEnd.In(boundLocation(pcmeStart, pcmeEnd)))
 
 			if !rawInserted {
 				mergedSchedule = append(mergedSchedule, rawEvent, pcmePart)
@@ -298,7 +422,7 @@ func (e *Engine) merge(rawEvent Event, PCMEs []Event) (mergedSchedule []Event) {
 
 		// "1.a": rawEvent (more desirable): [----)
 		//	      PCME (less desirable)    :       [----)
-		if rawEnd.Before(pcmeStart) || rawEnd.Equal(pcmeStart) {
+		if overlap == OverlapNoneBefore {
 			// The rawEvent does not overlap with the current PCME. Therefore, we can safely insert the
 			// rawEvent before the current PCME and safely move on. There will not be any other PCMEs
 			// that overlap with the rawEvent.
@@ -336,14 +460,14 @@ func findLastSafeMergedEventIndex(rawEvent Event, mergedEvents []Event) int {
 		return lastSafeMergedEventIndex
 	}
 
-	rawStart := rawEvent.GetStartTime()
+	rawStart := startOf(rawEvent)
 	for mergedEventIndex, mergedEvent := range mergedEvents {
-		mergedEnd := mergedEvent.GetEndTime()
+		mergedEnd := endOf(mergedEvent)
 		if mergedEnd.Before(rawStart) || mergedEnd.Equal(rawStart) {
 			lastSafeMergedEventIndex = mergedEventIndex
 			continue
 		}
-		// Because the events in `e.MergedSchedule` are sorted by StartTime/EndTime from oldest to newest, we can
+		// Because the events in `e.merged` are sorted by StartTime/EndTime from oldest to newest, we can
 		// safely break the loop as soon as we find the first event that is not completely before the rawEvent.
 		break
 	}
@@ -353,7 +477,8 @@ func findLastSafeMergedEventIndex(rawEvent Event, mergedEvents []Event) int {
 
 func splitMergedEventsOnSafeInsert(lastSafeMergedEventIndex int, mergedEvents []Event) (safe, potentialConflict []Event) {
 	if lastSafeMergedEventIndex == -1 {
-		return []Event{}, mergedEvents
+		// The empty safe slice keeps the backing array of the merged events, so that they are rebuilt in place.
+		return mergedEvents[:0], mergedEvents
 	}
 
 	if len(mergedEvents) == lastSafeMergedEventIndex+1 {
@@ -1,6 +1,9 @@
 package scheduleMerge
 
 import (
+	"context"
+	"errors"
+	"math/rand"
 	"sort"
 	"testing"
 	"time"
@@ -56,6 +59,50 @@ func (s schedule) GetEvents() []Event {
 	return events
 }
 
+// orderedSchedule is a Schedule that is already sorted by desirability in ascending order.
+type orderedSchedule []Event
+
+func (s orderedSchedule) SortByDesirability() {}
+
+func (s orderedSchedule) GetEvents() []Event {
+	return s
+}
+
+// randomSchedule returns n events with random (hour aligned) bounds and random desirabilities, spread over the
+// given number of hours. The same seed always yields the same schedule.
+func randomSchedule(seed int64, n, hours int) schedule {
+	var (
+		r      = rand.New(rand.NewSource(seed))
+		origin = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		s      = make(schedule, n)
+	)
+	for i := range s {
+		start := r.Intn(hours)
+		s[i] = &event{
+			StartTime: origin.Add(time.Duration(start) * time.Hour),
+			EndTime:   origin.Add(time.Duration(start+1+r.Intn(6)) * time.Hour),
+			CreatedAt: origin.Add(time.Duration(r.Intn(n)) * time.Minute),
+			ID:        i + 1,
+		}
+	}
+	return s
+}
+
+// at returns the time the given number of hours after the start of 2020-01-01 (UTC), the day most tests take place
+// on.
+func at(hour int) time.Time {
+	return time.Date(2020, 1, 1, hour, 0, 0, 0, time.UTC)
+}
+
+// mergedEvents dereferences the merged events so that they can be compared by value.
+func mergedEvents(merged []Event) []event {
+	evs := make([]event, len(merged))
+	for i := range merged {
+		evs[i] = *(merged[i].(*event))
+	}
+	return evs
+}
+
 func TestEngine_Merge(t *testing.T) {
 	// Event Overlap Types:
 	// 1. No overlap :
@@ -4568,6 +4615,46 @@ func TestEngine_Merge(t *testing.T) {
 			},
 			trimOverlaps: true,
 		},
+		{
+			// most desirable event:    [-)
+			// less desirable events: [-----) [--)
+			name: "3 events-[3.c]-no trim-keeps the events after the overlap",
+			testSchedule: schedule{
+				{
+					StartTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+					EndTime:   time.Date(2020, 1, 1, 4, 0, 0, 0, time.UTC),
+					CreatedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+					ID:        1,
+				},
+				{
+					StartTime: time.Date(2020, 1, 1, 5, 0, 0, 0, time.UTC),
+					EndTime:   time.Date(2020, 1, 1, 6, 0, 0, 0, time.UTC),
+					CreatedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+					ID:        2,
+				},
+				{
+					StartTime: time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC),
+					EndTime:   time.Date(2020, 1, 1, 2, 0, 0, 0, time.UTC),
+					CreatedAt: time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC),
+					ID:        3,
+				},
+			},
+			expectedSchedule: []event{
+				{
+					StartTime: time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC),
+					EndTime:   time.Date(2020, 1, 1, 2, 0, 0, 0, time.UTC),
+					CreatedAt: time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC),
+					ID:        3,
+				},
+				{
+					StartTime: time.Date(2020, 1, 1, 5, 0, 0, 0, time.UTC),
+					EndTime:   time.Date(2020, 1, 1, 6, 0, 0, 0, time.UTC),
+					CreatedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+					ID:        2,
+				},
+			},
+			trimOverlaps: false,
+		},
 	}
 
 	for _, tc := range tcs {
@@ -4581,9 +4668,16 @@ func TestEngine_Merge(t *testing.T) {
 				evs[i] = *(mergedSchedule[i].(*event))
 			}
 
+			expectedEvents := make([]Event, len(tc.expectedSchedule))
+			for i := range tc.expectedSchedule {
+				expectedEvents[i] = &tc.expectedSchedule[i]
+			}
+
 			if len(evs) != len(tc.expectedSchedule) {
 				t.Logf("mergedSchedule:\n\t%+v\n", evs)
 				t.Logf("expectedSchedule:\n\t%+v\n", tc.expectedSchedule)
+				t.Logf("got:\n%s", e.Timeline(TimelineOptions{}))
+				t.Logf("expected:\n%s", RenderTimeline(e.RawSchedule, expectedEvents, TimelineOptions{}))
 				t.Fatalf("expected %d events, got %d", len(tc.expectedSchedule), len(mergedSchedule))
 			}
 
@@ -4598,8 +4692,67 @@ func TestEngine_Merge(t *testing.T) {
 			}
 
 			if failed {
+				t.Logf("got:\n%s", e.Timeline(TimelineOptions{}))
+				t.Logf("expected:\n%s", RenderTimeline(e.RawSchedule, expectedEvents, TimelineOptions{}))
 				t.Fatalf("expected merged schedule to be:\n\t%+v\ngot:\n\t%+v", tc.expectedSchedule, evs)
 			}
 		})
 	}
 }
+
+func TestEngine_MergeContext(t *testing.T) {
+	const n = 4 * progressInterval
+
+	expected := NewEngine(randomSchedule(1, n, 100), true)
+	expected.Merge()
+
+	t.Run("cancelled before merging", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		e := NewEngine(randomSchedule(1, n, 100), true)
+		if err := e.MergeContext(ctx); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected %v, got %v", context.Canceled, err)
+		}
+		if len(e.MergedSchedule) != 0 {
+			t.Fatalf("expected an empty merged schedule, got %d events", len(e.MergedSchedule))
+		}
+	})
+
+	t.Run("cancelled while merging and resumed", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var reports [][2]int
+		e := NewEngine(randomSchedule(1, n, 100), true)
+		e.OnProgress = func(processed, total int) {
+			reports = append(reports, [2]int{processed, total})
+			if processed == 2*progressInterval {
+				cancel()
+			}
+		}
+
+		if err := e.MergeContext(ctx); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected %v, got %v", context.Canceled, err)
+		}
+		// The merged schedule holds the least desirable events processed before the cancellation.
+		partial := NewEngine(randomSchedule(1, n, 100), true)
+		partial.RawSchedule = partial.RawSchedule[:2*progressInterval]
+		partial.Merge()
+		if diff := cmp.Diff(mergedEvents(partial.MergedSchedule), mergedEvents(e.MergedSchedule)); diff != "" {
+			t.Fatalf("unexpected merged schedule after the cancellation:\n%s", diff)
+		}
+
+		if err := e.MergeContext(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(mergedEvents(expected.MergedSchedule), mergedEvents(e.MergedSchedule)); diff != "" {
+			t.Fatalf("unexpected merged schedule after resuming:\n%s", diff)
+		}
+
+		expectedReports := [][2]int{{0, n}, {256, n}, {512, n}, {512, n}, {768, n}, {1024, n}}
+		if diff := cmp.Diff(expectedReports, reports); diff != "" {
+			t.Fatalf("unexpected progress reports:\n%s", diff)
+		}
+	})
+}
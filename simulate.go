@@ -0,0 +1,107 @@
+package scheduleMerge
+
+import (
+	"time"
+)
+
+// Simulation is what would happen if an event was inserted into the raw schedule of an Engine.
+type Simulation struct {
+	// MergedSchedule is the merged schedule the engine would have.
+	MergedSchedule []Event
+	// Conflicts are the conflicts the engine would have.
+	Conflicts []Conflict
+	// Changes are the changes to the current merged schedule.
+	Changes ChangeSet
+	// Losses are the other raw events that would lose time, in the order of the raw schedule.
+	Losses []Loss
+}
+
+// Loss is the time a raw event would lose in the merged schedule.
+type Loss struct {
+	// Event is the raw event.
+	Event Event
+	// Lost is the time the event would lose. It is the maximum Duration if the event would lose a part without a
+	// start or without an end (see UnboundedEvent).
+	Lost time.Duration
+	// Removed indicates that no part of the event would be left.
+	Removed bool
+}
+
+// Simulate reports what would happen if the event was inserted at the given rank (see Insert), without changing the
+// engine: MergedSchedule, RawSchedule and Conflicts stay as they are, and nothing is recorded in a Journal or for
+// Undo. The Changes of the Simulation are relative to the result of merging the current raw schedule, even if the
+// engine has not finished merging it yet.
+func (e *Engine) Simulate(rank int, event Event) (*Simulation, error) {
+	before := e.clone()
+	before.Merge()
+
+	after := before.clone()
+	if err := after.Insert(rank, event); err != nil {
+		return nil, err
+	}
+	after.Merge()
+
+	simulation := &Simulation{
+		MergedSchedule: after.MergedSchedule,
+		Conflicts:      after.Conflicts,
+		Changes:        after.Snapshot().Diff(before.Snapshot()),
+	}
+
+	keptBefore, keptAfter := before.keptDurations(), after.keptDurations()
+	for _, rawEvent := range before.RawSchedule {
+		kept, ok := keptBefore.lookup(rawEvent)
+		if !ok {
+			continue
+		}
+
+		left, ok := keptAfter.lookup(rawEvent)
+		if left >= kept {
+			continue
+		}
+		lost := kept - left
+		if kept == maxDuration {
+			lost = maxDuration
+		}
+		simulation.Losses = append(simulation.Losses, Loss{Event: rawEvent, Lost: lost, Removed: !ok})
+	}
+	return simulation, nil
+}
+
+// clone returns an engine with the same state that can be merged without changing this one. It neither records in a
+// Journal nor for Undo, and does not report its progress or notify the Observers.
+func (e *Engine) clone() *Engine {
+	c := &Engine{
+		Constraints: e.Constraints,
+		CloneEvent:  e.CloneEvent,
+		Granularity: e.Granularity,
+		MinFragment: e.MinFragment,
+		sources:     e.sources,
+	}
+	c.restore(e.state())
+	if e.origins != nil {
+		c.origins = make(eventMap[Event], len(e.origins))
+		for part, origin := range e.origins {
+			c.origins[part] = origin
+		}
+	}
+	return c
+}
+
+// keptDurations returns the total duration of the parts of every raw event in the merged schedule. Parts without a
+// start or without an end count as the maximum Duration.
+func (e *Engine) keptDurations() eventMap[time.Duration] {
+	kept := eventMap[time.Duration]{}
+	for _, mergedEvent := range e.MergedSchedule {
+		rawEvent := e.original(mergedEvent)
+		duration := maxDuration
+		if start, end := startOf(mergedEvent), endOf(mergedEvent); !isUnbounded(start) && !isUnbounded(end) {
+			duration = end.Sub(start)
+		}
+		if kept.get(rawEvent) > maxDuration-duration {
+			kept.set(rawEvent, maxDuration)
+		} else {
+			kept.set(rawEvent, kept.get(rawEvent)+duration)
+		}
+	}
+	return kept
+}
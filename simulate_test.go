@@ -0,0 +1,128 @@
+package scheduleMerge
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEngine_Simulate(t *testing.T) {
+	newEngine := func(trimOverlaps bool) *Engine {
+		e := NewEngine(schedule{
+			{StartTime: at(0), EndTime: at(4), CreatedAt: at(0), ID: 1},
+			{StartTime: at(4), EndTime: at(6), CreatedAt: at(1), ID: 2},
+			{StartTime: at(7), EndTime: at(8), CreatedAt: at(2), ID: 3},
+		}, trimOverlaps)
+		e.Merge()
+		return e
+	}
+	// loss describes a Loss by the ID of the event.
+	type loss struct {
+		ID      int
+		Lost    time.Duration
+		Removed bool
+	}
+
+	tests := []struct {
+		name         string
+		trimOverlaps bool
+		rank         int
+		event        *event
+		losses       []loss
+		added        int
+		removed      int
+		resized      int
+	}{
+		{
+			name:         "most desirable-trim",
+			trimOverlaps: true,
+			rank:         3,
+			event:        &event{StartTime: at(3), EndTime: at(5), ID: 4},
+			losses:       []loss{{ID: 1, Lost: time.Hour}, {ID: 2, Lost: time.Hour}},
+			added:        1,
+			resized:      2,
+		},
+		{
+			name:         "most desirable-no trim",
+			trimOverlaps: false,
+			rank:         3,
+			event:        &event{StartTime: at(3), EndTime: at(5), ID: 4},
+			losses:       []loss{{ID: 1, Lost: 4 * time.Hour, Removed: true}, {ID: 2, Lost: 2 * time.Hour, Removed: true}},
+			added:        1,
+			removed:      2,
+		},
+		{
+			name:         "least desirable",
+			trimOverlaps: true,
+			rank:         0,
+			event:        &event{StartTime: at(5), EndTime: at(9), ID: 4},
+			// The parts between the more desirable events.
+			added: 2,
+		},
+		{
+			name:         "no overlap",
+			trimOverlaps: true,
+			rank:         3,
+			event:        &event{StartTime: at(10), EndTime: at(11), ID: 4},
+			added:        1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEngine(tt.trimOverlaps)
+			raw := append([]Event(nil), e.RawSchedule...)
+			merged := mergedEvents(e.MergedSchedule)
+			conflicts := len(e.Conflicts)
+
+			simulation, err := e.Simulate(tt.rank, tt.event)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var losses []loss
+			for _, l := range simulation.Losses {
+				losses = append(losses, loss{ID: l.Event.(*event).ID, Lost: l.Lost, Removed: l.Removed})
+			}
+			if diff := cmp.Diff(tt.losses, losses); diff != "" {
+				t.Errorf("unexpected losses:\n%s", diff)
+			}
+			changes := simulation.Changes
+			if len(changes.Added) != tt.added || len(changes.Removed) != tt.removed || len(changes.Resized) != tt.resized {
+				t.Errorf("expected %d added, %d removed and %d resized events, got %d, %d and %d", tt.added, tt.removed,
+					tt.resized, len(changes.Added), len(changes.Removed), len(changes.Resized))
+			}
+
+			// The simulation is what merging after inserting the event yields.
+			expected := newEngine(tt.trimOverlaps)
+			if err := expected.Insert(tt.rank, tt.event); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected.Merge()
+			if diff := cmp.Diff(mergedEvents(expected.MergedSchedule), mergedEvents(simulation.MergedSchedule)); diff != "" {
+				t.Errorf("unexpected merged schedule:\n%s", diff)
+			}
+			if len(expected.Conflicts) != len(simulation.Conflicts) {
+				t.Errorf("expected %d conflicts, got %d", len(expected.Conflicts), len(simulation.Conflicts))
+			}
+
+			// The engine is unchanged.
+			if diff := cmp.Diff(raw, e.RawSchedule); diff != "" {
+				t.Errorf("unexpected raw schedule:\n%s", diff)
+			}
+			if diff := cmp.Diff(merged, mergedEvents(e.MergedSchedule)); diff != "" {
+				t.Errorf("unexpected merged schedule:\n%s", diff)
+			}
+			if len(e.Conflicts) != conflicts || len(e.origins) != 0 {
+				t.Errorf("expected the conflicts and origins of the engine to be unchanged")
+			}
+		})
+	}
+
+	t.Run("invalid rank", func(t *testing.T) {
+		if _, err := newEngine(true).Simulate(4, &event{StartTime: at(0), EndTime: at(1)}); !errors.Is(err, ErrInvalidRank) {
+			t.Fatalf("expected %v, got %v", ErrInvalidRank, err)
+		}
+	})
+}
@@ -0,0 +1,241 @@
+package scheduleMerge
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrUnorderedStream is returned when an event is pushed to a StreamMerger that starts before an event pushed earlier.
+var ErrUnorderedStream = errors.New("scheduleMerge: stream is not ordered by start time")
+
+// StreamMerger merges events that arrive ordered by their start time rather than by their desirability, e.g. rows
+// read from a database cursor, without holding all of them in memory. It emits the merged events as soon as no later
+// event can change them, and only keeps the events that are still active, i.e. that end after the start of the last
+// pushed event.
+//
+// Since the events do not arrive in the order of their desirability, the desirability has to be decided for every
+// pair of events on its own: Less reports whether a is less desirable than b. Events of the same desirability are
+// ordered by their arrival, the later event being more desirable (like a stable SortByDesirability would order them).
+// Pinned events (see PolicyPinned) are more desirable than all the other events.
+//
+// The merged events are exactly the ones an Engine with the same TrimOverlaps would produce for the same events
+// (honouring the Policy of every event), and they are emitted in the order of the merged schedule. Conflicts are not
+// recorded.
+type StreamMerger struct {
+	// Less reports whether the event a is less desirable than the event b.
+	Less func(a, b Event) bool
+	// Indicates whether the overlaps between the events are trimmed (true) or the less desirable conflicting events are
+	// discarded (false).
+	TrimOverlaps bool
+	// The time zone the dates of AllDayEvent(s) are resolved in. Defaults to UTC if nil.
+	Location *time.Location
+
+	// active holds the pushed events that might still affect the merged events to come.
+	active []*streamEvent
+	// pushed is the number of events pushed so far. It orders the events of the same desirability.
+	pushed int
+	// cursor is the start of the last pushed event. Everything before it is final.
+	cursor time.Time
+	// The run of the merged schedule being built: owner is the most desirable event from runStart up to the cursor.
+	owner    *streamEvent
+	runStart time.Time
+}
+
+// streamEvent is an event held by a StreamMerger.
+type streamEvent struct {
+	event      Event
+	start, end time.Time
+	arrival    int
+	// trims indicates whether the event is trimmed when it loses an overlap (see Policy).
+	trims bool
+	// discarded is set once a more desirable event overlapping an event that is not trimmed was pushed.
+	discarded bool
+}
+
+// NewStreamMerger creates a StreamMerger that orders the desirability of the events by less.
+func NewStreamMerger(less func(a, b Event) bool, trimOverlaps bool) *StreamMerger {
+	return &StreamMerger{
+		Less:         less,
+		TrimOverlaps: trimOverlaps,
+	}
+}
+
+// Push adds the next event and returns the merged events that became final because of it. The event must not start
+// before any event pushed earlier; otherwise ErrUnorderedStream is returned and the event is ignored.
+func (s *StreamMerger) Push(event Event) ([]Event, error) {
+	s.resolve(event)
+	start := startOf(event)
+	if s.pushed > 0 && start.Before(s.cursor) {
+		return nil, fmt.Errorf("%w: %v is before %v", ErrUnorderedStream, exposedBound(start), exposedBound(s.cursor))
+	}
+
+	var merged []Event
+	if s.pushed > 0 {
+		merged = s.advance(start)
+	}
+	s.cursor = start
+
+	pushed := &streamEvent{
+		event:   event,
+		start:   start,
+		end:     endOf(event),
+		arrival: s.pushed,
+		trims:   trimsWith(event, s.TrimOverlaps),
+	}
+	s.pushed++
+	// All the active events overlap the pushed one: they end after its start and do not start after it.
+	for _, active := range s.active {
+		if s.moreDesirable(active, pushed) {
+			pushed.discarded = !pushed.trims
+		} else if !active.trims {
+			active.discarded = true
+		}
+	}
+	s.active = append(s.active, pushed)
+	return merged, nil
+}
+
+// Flush returns the remaining merged events once all the events have been pushed. The StreamMerger can be used for
+// another stream afterwards.
+func (s *StreamMerger) Flush() []Event {
+	merged := s.advance(unboundedEnd)
+	*s = StreamMerger{Less: s.Less, TrimOverlaps: s.TrimOverlaps, Location: s.Location}
+	return merged
+}
+
+// advance finalizes the merged events up to limit. No event pushed later starts before limit.
+//
+// At any time, the merged schedule holds the most desirable event covering that time, unless that event was
+// discarded: an event that is trimmed loses exactly the times covered by more desirable events, and an event that is
+// not trimmed is discarded by any more desirable event overlapping it (and then leaves a gap). Up to limit, the active
+// events all start at or before the cursor, so the most desirable of them only changes when one of them ends.
+func (s *StreamMerger) advance(limit time.Time) []Event {
+	var merged []Event
+	t := s.cursor
+	for t.Before(limit) && len(s.active) > 0 {
+		var owner *streamEvent
+		next := limit
+		for _, active := range s.active {
+			if owner == nil || s.moreDesirable(active, owner) {
+				owner = active
+			}
+			if active.end.Before(next) {
+				next = active.end
+			}
+		}
+
+		if owner != s.owner {
+			merged = s.closeRun(merged, t)
+			s.owner, s.runStart = owner, t
+		}
+
+		t = next
+		s.removeEnded(t)
+	}
+
+	// Nothing covers the time between the last end and the limit.
+	if len(s.active) == 0 {
+		merged = s.closeRun(merged, t)
+		s.owner = nil
+	}
+	return merged
+}
+
+// closeRun appends the part of the owner from the start of its run up to end, unless the owner was discarded. Every
+// event overlapping the owner before end has been pushed, so whether it was discarded is final.
+func (s *StreamMerger) closeRun(merged []Event, end time.Time) []Event {
+	if s.owner == nil || s.owner.discarded {
+		return merged
+	}
+	if end.After(s.owner.end) {
+		end = s.owner.end
+	}
+	return append(merged, s.part(s.owner, s.runStart, end))
+}
+
+// removeEnded removes the active events that end at or before t.
+func (s *StreamMerger) removeEnded(t time.Time) {
+	active := s.active[:0]
+	for _, event := range s.active {
+		if event.end.After(t) {
+			active = append(active, event)
+		}
+	}
+	for i := len(active); i < len(s.active); i++ {
+		s.active[i] = nil
+	}
+	s.active = active
+}
+
+// part returns the part [start, end) of the event. The event itself is returned if it was not trimmed.
+func (s *StreamMerger) part(event *streamEvent, start, end time.Time) Event {
+	if start.Equal(event.start) && end.Equal(event.end) {
+		return event.event
+	}
+
+	part := event.event.Clone()
+	if !start.Equal(event.start) {
+		part.SetStartTime(start.In(boundLocation(event.start, event.end)))
+	}
+	if !end.Equal(event.end) {
+		part.SetEndTime(end.In(boundLocation(event.end, event.start)))
+	}
+	return part
+}
+
+// moreDesirable reports whether the event a is more desirable than the event b.
+func (s *StreamMerger) moreDesirable(a, b *streamEvent) bool {
+	if aPinned, bPinned := isPinned(a.event), isPinned(b.event); aPinned != bPinned {
+		return aPinned
+	}
+	if s.Less(b.event, a.event) {
+		return true
+	}
+	if s.Less(a.event, b.event) {
+		return false
+	}
+	return a.arrival > b.arrival
+}
+
+// resolve stores the instants of the bounds of WallClockEvent(s) and AllDayEvent(s).
+func (s *StreamMerger) resolve(event Event) {
+	resolveWallClock(event)
+	resolveAllDay(event, s.Location)
+}
+
+// MergeStream merges the events yielded by events, which must be ordered by their start time (see StreamMerger), and
+// yields the merged events as soon as they are final. If an event starts before an event yielded earlier, the
+// ErrUnorderedStream is yielded and merging stops.
+//
+// Both functions have the shape of iter.Seq and iter.Seq2, so they can be used with range-over-func.
+func MergeStream(events func(yield func(Event) bool), less func(a, b Event) bool, trimOverlaps bool) func(yield func(Event, error) bool) {
+	return func(yield func(Event, error) bool) {
+		s := NewStreamMerger(less, trimOverlaps)
+		stopped := false
+		events(func(event Event) bool {
+			merged, err := s.Push(event)
+			if err != nil {
+				stopped = true
+				yield(nil, err)
+				return false
+			}
+			for _, mergedEvent := range merged {
+				if !yield(mergedEvent, nil) {
+					stopped = true
+					return false
+				}
+			}
+			return true
+		})
+		if stopped {
+			return
+		}
+
+		for _, mergedEvent := range s.Flush() {
+			if !yield(mergedEvent, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package scheduleMerge
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// byStartTime returns the events of the schedule ordered by their start time.
+func byStartTime(s schedule) schedule {
+	sorted := append(schedule{}, s...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].StartTime.Before(sorted[j].StartTime)
+	})
+	return sorted
+}
+
+// lessCreatedAt orders the events of the tests by their desirability.
+func lessCreatedAt(a, b Event) bool {
+	return a.(*event).CreatedAt.Before(b.(*event).CreatedAt)
+}
+
+func TestStreamMerger(t *testing.T) {
+	for seed := int64(1); seed <= 100; seed++ {
+		for _, trimOverlaps := range []bool{true, false} {
+			t.Run(fmt.Sprintf("seed %d-trim %t", seed, trimOverlaps), func(t *testing.T) {
+				// The engine breaks ties in desirability by the order of the raw schedule, the stream by the order
+				// of arrival.
+				expected := NewEngine(byStartTime(randomSchedule(seed, 200, 100)), trimOverlaps)
+				expected.Merge()
+
+				s := NewStreamMerger(lessCreatedAt, trimOverlaps)
+				var merged []Event
+				for _, ev := range byStartTime(randomSchedule(seed, 200, 100)) {
+					final, err := s.Push(ev)
+					if err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+					merged = append(merged, final...)
+				}
+				merged = append(merged, s.Flush()...)
+
+				if diff := cmp.Diff(mergedEvents(expected.MergedSchedule), mergedEvents(merged)); diff != "" {
+					t.Fatalf("unexpected merged schedule:\n%s", diff)
+				}
+			})
+		}
+	}
+}
+
+func TestStreamMerger_KeepsOnlyActiveEvents(t *testing.T) {
+	// Back to back events: every event is final as soon as the next one starts.
+	s := NewStreamMerger(lessCreatedAt, true)
+	origin := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 1000; i++ {
+		final, err := s.Push(&event{
+			StartTime: origin.Add(time.Duration(i) * time.Hour),
+			EndTime:   origin.Add(time.Duration(i+1) * time.Hour),
+			ID:        i,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if expected := min(i, 1); len(final) != expected {
+			t.Fatalf("expected %d final events after event %d, got %d", expected, i, len(final))
+		}
+		if len(s.active) != 1 {
+			t.Fatalf("expected a single active event, got %d", len(s.active))
+		}
+	}
+	if final := s.Flush(); len(final) != 1 {
+		t.Fatalf("expected the last event to be flushed, got %d events", len(final))
+	}
+}
+
+func TestStreamMerger_Unordered(t *testing.T) {
+	s := NewStreamMerger(lessCreatedAt, true)
+	origin := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := s.Push(&event{StartTime: origin.Add(time.Hour), EndTime: origin.Add(2 * time.Hour)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := s.Push(&event{StartTime: origin, EndTime: origin.Add(time.Hour)}); !errors.Is(err, ErrUnorderedStream) {
+		t.Fatalf("expected %v, got %v", ErrUnorderedStream, err)
+	}
+}
+
+func TestMergeStream(t *testing.T) {
+	in := byStartTime(randomSchedule(1, 500, 200))
+	events := func(yield func(Event) bool) {
+		for _, ev := range in {
+			if !yield(ev) {
+				return
+			}
+		}
+	}
+
+	expected := NewEngine(byStartTime(randomSchedule(1, 500, 200)), true)
+	expected.Merge()
+
+	var merged []Event
+	MergeStream(events, lessCreatedAt, true)(func(ev Event, err error) bool {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		merged = append(merged, ev)
+		return true
+	})
+	if diff := cmp.Diff(mergedEvents(expected.MergedSchedule), mergedEvents(merged)); diff != "" {
+		t.Fatalf("unexpected merged schedule:\n%s", diff)
+	}
+
+	t.Run("stops early", func(t *testing.T) {
+		count := 0
+		MergeStream(events, lessCreatedAt, true)(func(Event, error) bool {
+			count++
+			return count < 3
+		})
+		if count != 3 {
+			t.Fatalf("expected 3 merged events to be yielded, got %d", count)
+		}
+	})
+
+	t.Run("unordered", func(t *testing.T) {
+		unordered := func(yield func(Event) bool) {
+			for i := len(in) - 1; i >= 0; i-- {
+				if !yield(in[i]) {
+					return
+				}
+			}
+		}
+		var errs []error
+		MergeStream(unordered, lessCreatedAt, true)(func(_ Event, err error) bool {
+			if err != nil {
+				errs = append(errs, err)
+			}
+			return true
+		})
+		if len(errs) != 1 || !errors.Is(errs[0], ErrUnorderedStream) {
+			t.Fatalf("expected a single %v, got %v", ErrUnorderedStream, errs)
+		}
+	})
+}
+
+func TestStreamMerger_Unbounded(t *testing.T) {
+	origin := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	newEvents := func() []Event {
+		return []Event{
+			&unboundedEvent{event: event{EndTime: origin.Add(2 * time.Hour), ID: 1}, NoStart: true},
+			&unboundedEvent{event: event{StartTime: origin, EndTime: origin.Add(4 * time.Hour), ID: 2, CreatedAt: origin.Add(time.Minute)}},
+			&unboundedEvent{event: event{StartTime: origin.Add(3 * time.Hour), ID: 3}, NoEnd: true},
+		}
+	}
+	less := func(a, b Event) bool {
+		return a.(*unboundedEvent).CreatedAt.Before(b.(*unboundedEvent).CreatedAt)
+	}
+	unboundedEvents := func(merged []Event) []unboundedEvent {
+		evs := make([]unboundedEvent, len(merged))
+		for i := range merged {
+			evs[i] = *(merged[i].(*unboundedEvent))
+		}
+		return evs
+	}
+
+	for _, trimOverlaps := range []bool{true, false} {
+		t.Run(fmt.Sprintf("trim %t", trimOverlaps), func(t *testing.T) {
+			raw := newEvents()
+			expected := NewEngine(orderedSchedule{raw[0], raw[2], raw[1]}, trimOverlaps)
+			expected.Merge()
+
+			s := NewStreamMerger(less, trimOverlaps)
+			var merged []Event
+			for _, ev := range newEvents() {
+				final, err := s.Push(ev)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				merged = append(merged, final...)
+			}
+			merged = append(merged, s.Flush()...)
+
+			if diff := cmp.Diff(unboundedEvents(expected.MergedSchedule), unboundedEvents(merged), cmp.AllowUnexported(unboundedEvent{})); diff != "" {
+				t.Fatalf("unexpected merged schedule:\n%s", diff)
+			}
+		})
+	}
+}
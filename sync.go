@@ -0,0 +1,118 @@
+package scheduleMerge
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Snapshot is a consistent, read-only view of an Engine at a point in time. The slices of a Snapshot are never
+// modified after it has been published and must not be modified by its readers either.
+type Snapshot struct {
+	// The raw schedule, sorted by desirability in ascending order.
+	RawSchedule []Event
+	// The merged schedule of RawSchedule.
+	MergedSchedule []Event
+	// The conflicts that were resolved while creating MergedSchedule.
+	Conflicts []Conflict
+	// Indicates whether the overlaps in MergedSchedule were trimmed.
+	TrimOverlaps bool
+
+	// origins maps the trimmed parts in MergedSchedule to the raw events they originate from.
+	origins eventMap[Event]
+	// sources maps the raw events to the Source of their Layer. It is shared with the engine, which never changes it.
+	sources eventMap[string]
+}
+
+// Snapshot copies the current state of the engine, e.g. to Diff it with the state after the next Merge. The engine
+// reuses its slices when it merges incrementally, so the Snapshot does not share them.
+func (e *Engine) Snapshot() *Snapshot {
+	origins := eventMap[Event]{}
+	for _, mergedEvent := range e.MergedSchedule {
+		if origin, ok := e.origins.lookup(mergedEvent); ok {
+			origins.set(mergedEvent, origin)
+		}
+	}
+
+	return &Snapshot{
+		RawSchedule:    append([]Event(nil), e.RawSchedule...),
+		MergedSchedule: append([]Event(nil), e.MergedSchedule...),
+		Conflicts:      append([]Conflict(nil), e.Conflicts...),
+		TrimOverlaps:   e.TrimOverlaps,
+		origins:        origins,
+		sources:        e.sources,
+	}
+}
+
+// SyncEngine is an Engine that can be shared between goroutines, e.g. between HTTP handlers that add events and
+// handlers that read the merged schedule.
+//
+// Writers are serialised: every change is merged right away and published as a new Snapshot (copy-on-write).
+// Readers never block and always get a Snapshot that is consistent with a single point in time, no matter how many
+// writers are changing the engine concurrently.
+type SyncEngine struct {
+	// mu guards engine. Only writers take it.
+	mu       sync.Mutex
+	engine   *Engine
+	snapshot atomic.Pointer[Snapshot]
+}
+
+// NewSyncEngine creates a SyncEngine for the rawSchedule, merges it and publishes the first Snapshot.
+func NewSyncEngine(rawSchedule Schedule, trimOverlaps bool) *SyncEngine {
+	s := &SyncEngine{engine: NewEngine(rawSchedule, trimOverlaps)}
+	s.engine.Merge()
+	s.publish()
+	return s
+}
+
+// Snapshot returns the latest published Snapshot.
+func (s *SyncEngine) Snapshot() *Snapshot {
+	return s.snapshot.Load()
+}
+
+// Add adds the event as the most desirable event and publishes the result. See Engine.Add.
+func (s *SyncEngine) Add(event Event) {
+	s.update(func(e *Engine) {
+		e.Add(event)
+	})
+}
+
+// Insert inserts the event at the given rank and publishes the result. See Engine.Insert.
+func (s *SyncEngine) Insert(rank int, event Event) error {
+	var err error
+	s.update(func(e *Engine) {
+		err = e.Insert(rank, event)
+	})
+	return err
+}
+
+// Remove removes the event and publishes the result. It reports whether the event was part of the raw schedule. See
+// Engine.Remove.
+func (s *SyncEngine) Remove(event Event) bool {
+	var removed bool
+	s.update(func(e *Engine) {
+		removed = e.Remove(event)
+	})
+	return removed
+}
+
+// SetTrimOverlaps changes whether overlaps are trimmed and publishes the result. See Engine.SetTrimOverlaps.
+func (s *SyncEngine) SetTrimOverlaps(trimOverlaps bool) {
+	s.update(func(e *Engine) {
+		e.SetTrimOverlaps(trimOverlaps)
+	})
+}
+
+// update applies the change to the engine, merges it and publishes the result while holding the write lock.
+func (s *SyncEngine) update(change func(e *Engine)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	change(s.engine)
+	s.engine.Merge()
+	s.publish()
+}
+
+// publish publishes a Snapshot of the engine.
+func (s *SyncEngine) publish() {
+	s.snapshot.Store(s.engine.Snapshot())
+}
@@ -0,0 +1,103 @@
+package scheduleMerge
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// Run with -race to detect unsynchronised access.
+func TestSyncEngine_Concurrent(t *testing.T) {
+	const (
+		writers           = 4
+		readers           = 4
+		eventsPerWriter   = 50
+		readsPerReader    = 200
+		removeEveryNth    = 3
+		checkEveryNthRead = 20
+	)
+
+	s := NewSyncEngine(randomSchedule(1, 20, 48), true)
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i, ev := range randomSchedule(int64(w+2), eventsPerWriter, 48) {
+				s.Add(ev)
+				if i%removeEveryNth == 0 {
+					s.Remove(ev)
+				}
+				if i == eventsPerWriter/2 {
+					s.SetTrimOverlaps(w%2 == 0)
+				}
+			}
+		}(w)
+	}
+
+	errs := make(chan string, readers)
+	for r := 0; r < readers; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < readsPerReader; i++ {
+				snapshot := s.Snapshot()
+				merged := snapshot.MergedSchedule
+				for j := 1; j < len(merged); j++ {
+					if merged[j].GetStartTime().Before(merged[j-1].GetEndTime()) {
+						errs <- "snapshot with overlapping merged events"
+						return
+					}
+				}
+
+				if i%checkEveryNthRead != 0 {
+					continue
+				}
+				// The merged schedule of a snapshot is the merge of its raw schedule.
+				e := NewEngine(orderedSchedule(snapshot.RawSchedule), snapshot.TrimOverlaps)
+				e.Merge()
+				if diff := cmp.Diff(mergedEvents(e.MergedSchedule), mergedEvents(merged)); diff != "" {
+					errs <- "inconsistent snapshot:\n" + diff
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	snapshot := s.Snapshot()
+	if expected := 20 + writers*(eventsPerWriter-(eventsPerWriter+removeEveryNth-1)/removeEveryNth); len(snapshot.RawSchedule) != expected {
+		t.Fatalf("expected %d raw events, got %d", expected, len(snapshot.RawSchedule))
+	}
+}
+
+func TestSyncEngine_Insert(t *testing.T) {
+	s := NewSyncEngine(randomSchedule(1, 5, 10), false)
+	before := s.Snapshot()
+
+	if err := s.Insert(len(before.RawSchedule)+1, &event{}); err == nil {
+		t.Fatalf("expected an error for an invalid rank")
+	}
+	if s.Snapshot().RawSchedule == nil || len(s.Snapshot().RawSchedule) != len(before.RawSchedule) {
+		t.Fatalf("expected the raw schedule not to change")
+	}
+
+	added := randomSchedule(2, 1, 10)[0]
+	if err := s.Insert(0, added); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := s.Snapshot().RawSchedule[0]; got != Event(added) {
+		t.Fatalf("expected the inserted event to be the least desirable one")
+	}
+	// Published snapshots never change.
+	if len(before.RawSchedule) != 5 {
+		t.Fatalf("expected an earlier snapshot not to change")
+	}
+}
@@ -0,0 +1,69 @@
+package scheduleMerge
+
+import (
+	"time"
+)
+
+// UnboundedEvent is an optional interface for Event(s) that have no start (e.g. "blocked until further notice") or no
+// end (e.g. an ongoing maintenance or an on-call shift until further notice).
+//
+// An unbounded start lies before, and an unbounded end lies after, any other time; the value returned by
+// GetStartTime or GetEndTime for an unbounded side is ignored. When the Engine trims an UnboundedEvent, it calls
+// SetStartTime or SetEndTime on a Clone of it; afterwards the part has to report the side that was set as bounded.
+// The side that was not set stays unbounded.
+type UnboundedEvent interface {
+	Event
+	// HasStartTime reports whether the Event has a start.
+	HasStartTime() bool
+	// HasEndTime reports whether the Event has an end.
+	HasEndTime() bool
+}
+
+// maxDuration is the Duration of an overlap without a start or without an end.
+const maxDuration = time.Duration(1<<63 - 1)
+
+var (
+	// unboundedStart and unboundedEnd stand in for the missing bounds of an UnboundedEvent inside the engine. They
+	// are never set on an Event.
+	unboundedStart = time.Unix(-1<<62, 0).UTC()
+	unboundedEnd   = time.Unix(1<<62, 0).UTC()
+)
+
+// startOf returns the start time of the event, or unboundedStart if the event has no start.
+func startOf(event Event) time.Time {
+	if unboundedEvent, ok := event.(UnboundedEvent); ok && !unboundedEvent.HasStartTime() {
+		return unboundedStart
+	}
+	return event.GetStartTime()
+}
+
+// endOf returns the end time of the event, or unboundedEnd if the event has no end.
+func endOf(event Event) time.Time {
+	if unboundedEvent, ok := event.(UnboundedEvent); ok && !unboundedEvent.HasEndTime() {
+		return unboundedEnd
+	}
+	return event.GetEndTime()
+}
+
+// isUnbounded reports whether t stands in for a missing bound.
+func isUnbounded(t time.Time) bool {
+	return t.Equal(unboundedStart) || t.Equal(unboundedEnd)
+}
+
+// boundLocation returns the time zone of the bound t, or the time zone of the other bound of the same event if t is
+// unbounded.
+func boundLocation(t, other time.Time) *time.Location {
+	if isUnbounded(t) {
+		return other.Location()
+	}
+	return t.Location()
+}
+
+// exposedBound returns t, or the zero Time if t stands in for a missing bound. The bounds stored in the values the
+// Engine exposes (e.g. Conflict) use the zero Time for missing bounds.
+func exposedBound(t time.Time) time.Time {
+	if isUnbounded(t) {
+		return time.Time{}
+	}
+	return t
+}
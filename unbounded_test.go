@@ -0,0 +1,254 @@
+package scheduleMerge
+
+import (
+	"testing"
+	"time"
+)
+
+type unboundedEvent struct {
+	event
+	NoStart bool
+	NoEnd   bool
+}
+
+func (e *unboundedEvent) HasStartTime() bool {
+	return !e.NoStart
+}
+
+func (e *unboundedEvent) HasEndTime() bool {
+	return !e.NoEnd
+}
+
+func (e *unboundedEvent) SetStartTime(t time.Time) {
+	e.StartTime = t
+	e.NoStart = false
+}
+
+func (e *unboundedEvent) SetEndTime(t time.Time) {
+	e.EndTime = t
+	e.NoEnd = false
+}
+
+func (e *unboundedEvent) Clone() Event {
+	clone := *e
+	return &clone
+}
+
+func TestEngine_Merge_Unbounded(t *testing.T) {
+	tcs := []struct {
+		name         string
+		testSchedule func() orderedSchedule
+		trimOverlaps bool
+		// expected holds the bounds of the merged events. The zero Time stands for a missing bound.
+		expected [][2]time.Time
+	}{
+		{
+			// more desirable event (meeting):   [--)
+			// less desirable event (on-call): [-------->
+			name: "no end-[3.c]-trim",
+			testSchedule: func() orderedSchedule {
+				return orderedSchedule{
+					&unboundedEvent{
+						event: event{StartTime: time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC), ID: 1},
+						NoEnd: true,
+					},
+					&event{
+						StartTime: time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC),
+						EndTime:   time.Date(2020, 1, 1, 13, 0, 0, 0, time.UTC),
+						ID:        2,
+					},
+				}
+			},
+			trimOverlaps: true,
+			expected: [][2]time.Time{
+				{time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC), time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)},
+				{time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC), time.Date(2020, 1, 1, 13, 0, 0, 0, time.UTC)},
+				{time.Date(2020, 1, 1, 13, 0, 0, 0, time.UTC), {}},
+			},
+		},
+		{
+			// more desirable event (meeting)      :     [----)
+			// less desirable event (blocked until): <------)
+			name: "no start-[2.a]-trim",
+			testSchedule: func() orderedSchedule {
+				return orderedSchedule{
+					&unboundedEvent{
+						event:   event{EndTime: time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC), ID: 1},
+						NoStart: true,
+					},
+					&event{
+						StartTime: time.Date(2020, 1, 1, 8, 0, 0, 0, time.UTC),
+						EndTime:   time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC),
+						ID:        2,
+					},
+				}
+			},
+			trimOverlaps: true,
+			expected: [][2]time.Time{
+				{{}, time.Date(2020, 1, 1, 8, 0, 0, 0, time.UTC)},
+				{time.Date(2020, 1, 1, 8, 0, 0, 0, time.UTC), time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			// more desirable event:      [------>
+			// less desirable event: <----------->
+			name: "no start and no end-[3.c]-trim",
+			testSchedule: func() orderedSchedule {
+				return orderedSchedule{
+					&unboundedEvent{
+						event:   event{ID: 1},
+						NoStart: true,
+						NoEnd:   true,
+					},
+					&unboundedEvent{
+						event: event{StartTime: time.Date(2020, 1, 1, 11, 0, 0, 0, time.UTC), ID: 2},
+						NoEnd: true,
+					},
+				}
+			},
+			trimOverlaps: true,
+			expected: [][2]time.Time{
+				{{}, time.Date(2020, 1, 1, 11, 0, 0, 0, time.UTC)},
+				{time.Date(2020, 1, 1, 11, 0, 0, 0, time.UTC), {}},
+			},
+		},
+		{
+			// more desirable event: <----------->
+			// less desirable event:    [----)
+			name: "no start and no end-[3.b]-trim",
+			testSchedule: func() orderedSchedule {
+				return orderedSchedule{
+					&event{
+						StartTime: time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC),
+						EndTime:   time.Date(2020, 1, 1, 13, 0, 0, 0, time.UTC),
+						ID:        1,
+					},
+					&unboundedEvent{
+						event:   event{ID: 2},
+						NoStart: true,
+						NoEnd:   true,
+					},
+				}
+			},
+			trimOverlaps: true,
+			expected: [][2]time.Time{
+				{{}, {}},
+			},
+		},
+		{
+			// more desirable event (meeting):   [--)
+			// less desirable event (on-call): [-------->
+			name: "no end-[3.c]-no trim",
+			testSchedule: func() orderedSchedule {
+				return orderedSchedule{
+					&unboundedEvent{
+						event: event{StartTime: time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC), ID: 1},
+						NoEnd: true,
+					},
+					&event{
+						StartTime: time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC),
+						EndTime:   time.Date(2020, 1, 1, 13, 0, 0, 0, time.UTC),
+						ID:        2,
+					},
+				}
+			},
+			trimOverlaps: false,
+			expected: [][2]time.Time{
+				{time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC), time.Date(2020, 1, 1, 13, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			// more desirable event:       [------>
+			// less desirable event: [----)
+			name: "no end-[1.b]-trim",
+			testSchedule: func() orderedSchedule {
+				return orderedSchedule{
+					&event{
+						StartTime: time.Date(2020, 1, 1, 8, 0, 0, 0, time.UTC),
+						EndTime:   time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC),
+						ID:        1,
+					},
+					&unboundedEvent{
+						event: event{StartTime: time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC), ID: 2},
+						NoEnd: true,
+					},
+				}
+			},
+			trimOverlaps: true,
+			expected: [][2]time.Time{
+				{time.Date(2020, 1, 1, 8, 0, 0, 0, time.UTC), time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC)},
+				{time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC), {}},
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			e := NewEngine(tc.testSchedule(), tc.trimOverlaps)
+			e.Merge()
+
+			if len(e.MergedSchedule) != len(tc.expected) {
+				t.Fatalf("expected %d events, got %d:\n%s", len(tc.expected), len(e.MergedSchedule), e.Timeline(TimelineOptions{}))
+			}
+			for i, got := range e.MergedSchedule {
+				expected := tc.expected[i]
+				gotStart, gotEnd := exposedBound(startOf(got)), exposedBound(endOf(got))
+				if !gotStart.Equal(expected[0]) || !gotEnd.Equal(expected[1]) {
+					t.Errorf("event %d: expected [%s, %s), got [%s, %s)\n%s", i+1, expected[0], expected[1],
+						gotStart, gotEnd, e.Timeline(TimelineOptions{}))
+				}
+			}
+		})
+	}
+}
+
+func TestEngine_Conflicts_Unbounded(t *testing.T) {
+	// more desirable event: <----------->
+	// less desirable event:      [------>
+	e := NewEngine(orderedSchedule{
+		&unboundedEvent{
+			event: event{StartTime: time.Date(2020, 1, 1, 11, 0, 0, 0, time.UTC), ID: 1},
+			NoEnd: true,
+		},
+		&unboundedEvent{
+			event:   event{ID: 2},
+			NoStart: true,
+			NoEnd:   true,
+		},
+	}, true)
+	e.Merge()
+
+	if len(e.Conflicts) != 1 {
+		t.Fatalf("expected 1 conflict, got %d", len(e.Conflicts))
+	}
+	conflict := e.Conflicts[0]
+	if !conflict.Start.Equal(time.Date(2020, 1, 1, 11, 0, 0, 0, time.UTC)) || !conflict.End.IsZero() {
+		t.Errorf("expected the overlap [11:00, ...), got [%s, %s)", conflict.Start, conflict.End)
+	}
+	if conflict.Duration() != maxDuration {
+		t.Errorf("expected the maximum duration for an overlap without an end, got %s", conflict.Duration())
+	}
+}
+
+func TestRenderTimeline_Unbounded(t *testing.T) {
+	e := NewEngine(orderedSchedule{
+		&unboundedEvent{
+			event: event{StartTime: time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC), ID: 1},
+			NoEnd: true,
+		},
+		&event{
+			StartTime: time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2020, 1, 1, 13, 0, 0, 0, time.UTC),
+			ID:        2,
+		},
+	}, true)
+	e.Merge()
+
+	expected := "origin 2020-01-01T10:00:00Z, 1 column = 1h0m0s\n" +
+		"raw 1  |  |\n" +
+		"raw 0  |[-->\n" +
+		"merged |[)|>\n"
+	if got := e.Timeline(TimelineOptions{Scale: time.Hour}); got != expected {
+		t.Fatalf("expected timeline:\n%s\ngot:\n%s", expected, got)
+	}
+}
@@ -0,0 +1,94 @@
+package scheduleMerge
+
+import (
+	"time"
+)
+
+// engineState is everything Undo and Redo restore: the raw schedule, the settings and the (possibly partial) result
+// of merging them.
+type engineState struct {
+	rawSchedule     []Event
+	merged          []Event
+	mergedSchedule  []Event
+	conflicts       []Conflict
+	violations      []Violation
+	trimOverlaps    bool
+	location        *time.Location
+	processed       int
+	mergingFinished bool
+	origins         eventMap[Event]
+}
+
+// state copies the state of the engine. The engine changes its slices in place, so they are copied. The origins are
+// only ever added to until the merge is reset, which replaces them, so they can be shared.
+func (e *Engine) state() engineState {
+	return engineState{
+		rawSchedule:     append([]Event(nil), e.RawSchedule...),
+		merged:          append([]Event(nil), e.merged...),
+		mergedSchedule:  append([]Event{}, e.MergedSchedule...),
+		conflicts:       append([]Conflict(nil), e.Conflicts...),
+		violations:      append([]Violation(nil), e.Violations...),
+		trimOverlaps:    e.TrimOverlaps,
+		location:        e.Location,
+		processed:       e.processed,
+		mergingFinished: e.mergingFinished,
+		origins:         e.origins,
+	}
+}
+
+// restore restores a state of the engine.
+func (e *Engine) restore(s engineState) {
+	e.RawSchedule = s.rawSchedule
+	e.merged = s.merged
+	e.MergedSchedule = s.mergedSchedule
+	e.Conflicts = s.conflicts
+	e.Violations = s.violations
+	e.TrimOverlaps = s.trimOverlaps
+	e.Location = s.location
+	e.processed = s.processed
+	e.mergingFinished = s.mergingFinished
+	e.origins = s.origins
+}
+
+// saveUndo saves the state of the engine before a change, so that Undo can restore it. It forgets the changes that
+// were undone, as they cannot be redone after another change.
+func (e *Engine) saveUndo() {
+	if e.UndoLimit <= 0 {
+		return
+	}
+
+	e.undo = append(e.undo, e.state())
+	if over := len(e.undo) - e.UndoLimit; over > 0 {
+		e.undo = append(e.undo[:0], e.undo[over:]...)
+	}
+	e.redo = nil
+}
+
+// Undo reverts the last change (Add, Insert, Remove, SetTrimOverlaps or SetLocation) and reports whether there was
+// one. The engine gets back exactly the merged schedule and the conflicts it had before the change, without merging
+// again. At most UndoLimit changes can be reverted.
+func (e *Engine) Undo() bool {
+	if len(e.undo) == 0 {
+		return false
+	}
+
+	e.record(Op{Kind: OpUndo})
+	e.redo = append(e.redo, e.state())
+	e.restore(e.undo[len(e.undo)-1])
+	e.undo = e.undo[:len(e.undo)-1]
+	return true
+}
+
+// Redo reapplies the last change reverted by Undo and reports whether there was one. The engine gets back exactly the
+// merged schedule and the conflicts it had when Undo was called. Any other change forgets the reverted changes.
+func (e *Engine) Redo() bool {
+	if len(e.redo) == 0 {
+		return false
+	}
+
+	e.record(Op{Kind: OpRedo})
+	e.undo = append(e.undo, e.state())
+	e.restore(e.redo[len(e.redo)-1])
+	e.redo = e.redo[:len(e.redo)-1]
+	return true
+}
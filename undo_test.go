@@ -0,0 +1,126 @@
+package scheduleMerge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEngine_Undo(t *testing.T) {
+	e := NewEngine(schedule{
+		{StartTime: at(0), EndTime: at(4), CreatedAt: at(0), ID: 1},
+		{StartTime: at(3), EndTime: at(6), CreatedAt: at(1), ID: 2},
+	}, true)
+	e.UndoLimit = 10
+	e.Merge()
+
+	type state struct {
+		Merged    []event
+		Conflicts int
+		Trim      bool
+	}
+	current := func() state {
+		return state{Merged: mergedEvents(e.MergedSchedule), Conflicts: len(e.Conflicts), Trim: e.TrimOverlaps}
+	}
+
+	states := []state{current()}
+	urgent := &event{StartTime: at(1), EndTime: at(5), CreatedAt: at(2), ID: 3}
+	e.Add(urgent)
+	e.Merge()
+	states = append(states, current())
+	e.SetTrimOverlaps(false)
+	e.Merge()
+	states = append(states, current())
+	e.Remove(urgent)
+	e.Merge()
+	states = append(states, current())
+
+	// Undoing restores the merged schedules without merging again.
+	merges := 0
+	e.OnProgress = func(int, int) { merges++ }
+	for i := len(states) - 2; i >= 0; i-- {
+		if !e.Undo() {
+			t.Fatalf("expected change %d to be undone", i+1)
+		}
+		e.Merge()
+		if diff := cmp.Diff(states[i], current()); diff != "" {
+			t.Fatalf("unexpected state after undoing change %d:\n%s", i+1, diff)
+		}
+	}
+	if e.Undo() {
+		t.Fatalf("expected nothing to undo")
+	}
+	for i := 1; i < len(states); i++ {
+		if !e.Redo() {
+			t.Fatalf("expected change %d to be redone", i)
+		}
+		e.Merge()
+		if diff := cmp.Diff(states[i], current()); diff != "" {
+			t.Fatalf("unexpected state after redoing change %d:\n%s", i, diff)
+		}
+	}
+	if e.Redo() {
+		t.Fatalf("expected nothing to redo")
+	}
+	if merges != 0 {
+		t.Fatalf("expected no merges, got %d", merges)
+	}
+
+	// Another change forgets the undone changes.
+	e.Undo()
+	e.Add(&event{StartTime: at(8), EndTime: at(9), CreatedAt: at(3), ID: 4})
+	if e.Redo() {
+		t.Fatalf("expected nothing to redo after another change")
+	}
+}
+
+func TestEngine_UndoLimit(t *testing.T) {
+	origin := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("disabled", func(t *testing.T) {
+		e := NewEngine(schedule{}, true)
+		e.Add(&event{StartTime: origin, EndTime: origin.Add(time.Hour)})
+		if e.Undo() {
+			t.Fatalf("expected undo to be disabled")
+		}
+	})
+
+	t.Run("limited", func(t *testing.T) {
+		e := NewEngine(schedule{}, true)
+		e.UndoLimit = 2
+		for i := 0; i < 5; i++ {
+			e.Add(&event{StartTime: origin.Add(time.Duration(i) * time.Hour), EndTime: origin.Add(time.Duration(i+1) * time.Hour)})
+		}
+		undone := 0
+		for e.Undo() {
+			undone++
+		}
+		if undone != 2 || len(e.RawSchedule) != 3 {
+			t.Fatalf("expected 2 changes to be undone leaving 3 events, got %d changes and %d events", undone, len(e.RawSchedule))
+		}
+	})
+}
+
+func TestJournal_ReplayUndo(t *testing.T) {
+	origin := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := NewEngine(schedule{}, true)
+	e.UndoLimit = 10
+	journal := e.StartJournal()
+
+	for i := 0; i < 3; i++ {
+		e.Add(&event{StartTime: origin, EndTime: origin.Add(time.Duration(i+1) * time.Hour), CreatedAt: origin.Add(time.Duration(i) * time.Minute), ID: i})
+	}
+	e.Undo()
+	e.Undo()
+	e.Redo()
+	e.Merge()
+
+	replayed, err := journal.Replay(len(journal.Ops))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(mergedEvents(e.MergedSchedule), mergedEvents(replayed.MergedSchedule)); diff != "" {
+		t.Fatalf("unexpected merged schedule:\n%s", diff)
+	}
+}
@@ -0,0 +1,108 @@
+package scheduleMerge
+
+import (
+	"time"
+)
+
+// WallClock is a date and a time of day as read from a wall clock, without a time zone. It only becomes an instant
+// once it is resolved in a time.Location by In.
+type WallClock struct {
+	Year   int
+	Month  time.Month
+	Day    int
+	Hour   int
+	Minute int
+	Second int
+}
+
+// WallClockOf returns the wall clock reading of t in the location of t.
+func WallClockOf(t time.Time) WallClock {
+	year, month, day := t.Date()
+	hour, minute, second := t.Clock()
+	return WallClock{Year: year, Month: month, Day: day, Hour: hour, Minute: minute, Second: second}
+}
+
+// In resolves the wall clock reading to an instant in loc. Around daylight saving time transitions a reading might
+// not exist or might exist twice in loc; these are resolved the same way as in RFC 5545 (iCalendar):
+//
+//   - A reading that was skipped when the clocks went forward is interpreted with the offset in effect before the
+//     transition, which moves it forward by the length of the gap (02:30 becomes 03:30 on a spring-forward day in
+//     Europe/Berlin).
+//   - A reading that was repeated when the clocks went back resolves to its first occurrence (02:30 is 02:30 CEST,
+//     not 02:30 CET, on a fall-back day in Europe/Berlin).
+//
+// Unlike time.Date, which does not guarantee how such readings are resolved, In always resolves them the same way.
+func (w WallClock) In(loc *time.Location) time.Time {
+	// The wall clock reading interpreted as if it was UTC. Subtracting the offset of loc yields the instant.
+	local := time.Date(w.Year, w.Month, w.Day, w.Hour, w.Minute, w.Second, 0, time.UTC)
+
+	// The offsets a day before and a day after the reading cover any single transition around it.
+	_, offsetBefore := local.Add(-24 * time.Hour).In(loc).Zone()
+	_, offsetAfter := local.Add(24 * time.Hour).In(loc).Zone()
+
+	var (
+		before      = local.Add(-time.Duration(offsetBefore) * time.Second)
+		after       = local.Add(-time.Duration(offsetAfter) * time.Second)
+		beforeValid = hasOffset(before, loc, offsetBefore)
+		afterValid  = hasOffset(after, loc, offsetAfter)
+	)
+
+	switch {
+	case beforeValid && afterValid:
+		// The reading is repeated (or there is no transition at all): take the first occurrence.
+		if after.Before(before) {
+			return after.In(loc)
+		}
+		return before.In(loc)
+	case afterValid:
+		return after.In(loc)
+	default:
+		// Either only the offset before the transition is valid, or the reading was skipped. In the latter case the
+		// offset before the transition moves the reading past the gap.
+		return before.In(loc)
+	}
+}
+
+// hasOffset reports whether the offset of loc at the instant t is offset (in seconds east of UTC).
+func hasOffset(t time.Time, loc *time.Location, offset int) bool {
+	_, actual := t.In(loc).Zone()
+	return actual == offset
+}
+
+// WallClockEvent is an optional interface for Event(s) that are defined by wall clock readings in a time zone
+// (e.g. "09:00 – 17:00 Europe/Berlin") rather than by instants.
+//
+// Before the Engine compares a WallClockEvent with any other event, it resolves its wall clock readings with
+// WallClock.In and stores the resulting instants via SetStartTime and SetEndTime. Parts of a trimmed WallClockEvent
+// are created by Clone and bounded via SetStartTime and SetEndTime like any other Event.
+type WallClockEvent interface {
+	Event
+	// GetLocation returns the time zone the wall clock readings of the Event are given in.
+	GetLocation() *time.Location
+	// GetStartWallClock returns the wall clock reading at the start of the Event.
+	GetStartWallClock() WallClock
+	// GetEndWallClock returns the wall clock reading at the end of the Event.
+	GetEndWallClock() WallClock
+}
+
+// resolveWallClock stores the instants of the wall clock readings of the event, if the event is a WallClockEvent.
+func resolveWallClock(event Event) {
+	wallClockEvent, ok := event.(WallClockEvent)
+	if !ok {
+		return
+	}
+
+	loc := wallClockEvent.GetLocation()
+	setBounds(event, wallClockEvent.GetStartWallClock().In(loc), wallClockEvent.GetEndWallClock().In(loc))
+}
+
+// setBounds sets the start and the end time of the event. Bounds that already hold the same instant in the same time
+// zone are not set again, so that merging an event again does not write to it.
+func setBounds(event Event, start, end time.Time) {
+	if current := event.GetStartTime(); !current.Equal(start) || current.Location() != start.Location() {
+		event.SetStartTime(start)
+	}
+	if current := event.GetEndTime(); !current.Equal(end) || current.Location() != end.Location() {
+		event.SetEndTime(end)
+	}
+}
@@ -0,0 +1,288 @@
+package scheduleMerge
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // The tests must not depend on the time zone database of the host.
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("failed to load location %q: %v", name, err)
+	}
+	return loc
+}
+
+type wallClockEvent struct {
+	event
+	Location *time.Location
+	Start    WallClock
+	End      WallClock
+}
+
+func (e *wallClockEvent) GetLocation() *time.Location {
+	return e.Location
+}
+
+func (e *wallClockEvent) GetStartWallClock() WallClock {
+	return e.Start
+}
+
+func (e *wallClockEvent) GetEndWallClock() WallClock {
+	return e.End
+}
+
+func (e *wallClockEvent) Clone() Event {
+	clone := *e
+	return &clone
+}
+
+func TestWallClock_In(t *testing.T) {
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+	newYork := mustLoadLocation(t, "America/New_York")
+
+	tcs := []struct {
+		name      string
+		wallClock WallClock
+		loc       *time.Location
+		expected  time.Time
+	}{
+		{
+			name:      "regular day",
+			wallClock: WallClock{Year: 2021, Month: time.June, Day: 1, Hour: 9},
+			loc:       berlin,
+			expected:  time.Date(2021, 6, 1, 7, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "spring forward-before the gap",
+			wallClock: WallClock{Year: 2021, Month: time.March, Day: 28, Hour: 1, Minute: 59},
+			loc:       berlin,
+			expected:  time.Date(2021, 3, 28, 0, 59, 0, 0, time.UTC),
+		},
+		{
+			name:      "spring forward-skipped hour",
+			wallClock: WallClock{Year: 2021, Month: time.March, Day: 28, Hour: 2, Minute: 30},
+			loc:       berlin,
+			// 03:30 CEST
+			expected: time.Date(2021, 3, 28, 1, 30, 0, 0, time.UTC),
+		},
+		{
+			name:      "spring forward-after the gap",
+			wallClock: WallClock{Year: 2021, Month: time.March, Day: 28, Hour: 3},
+			loc:       berlin,
+			expected:  time.Date(2021, 3, 28, 1, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "fall back-repeated hour",
+			wallClock: WallClock{Year: 2021, Month: time.October, Day: 31, Hour: 2, Minute: 30},
+			loc:       berlin,
+			// 02:30 CEST, the first occurrence.
+			expected: time.Date(2021, 10, 31, 0, 30, 0, 0, time.UTC),
+		},
+		{
+			name:      "fall back-after the repeated hour",
+			wallClock: WallClock{Year: 2021, Month: time.October, Day: 31, Hour: 3},
+			loc:       berlin,
+			expected:  time.Date(2021, 10, 31, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "spring forward-skipped hour-new york",
+			wallClock: WallClock{Year: 2021, Month: time.March, Day: 14, Hour: 2, Minute: 15},
+			loc:       newYork,
+			// 03:15 EDT
+			expected: time.Date(2021, 3, 14, 7, 15, 0, 0, time.UTC),
+		},
+		{
+			name:      "fall back-repeated hour-new york",
+			wallClock: WallClock{Year: 2021, Month: time.November, Day: 7, Hour: 1, Minute: 15},
+			loc:       newYork,
+			// 01:15 EDT, the first occurrence.
+			expected: time.Date(2021, 11, 7, 5, 15, 0, 0, time.UTC),
+		},
+		{
+			name:      "utc",
+			wallClock: WallClock{Year: 2021, Month: time.March, Day: 28, Hour: 2, Minute: 30},
+			loc:       time.UTC,
+			expected:  time.Date(2021, 3, 28, 2, 30, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.wallClock.In(tc.loc)
+			if !got.Equal(tc.expected) {
+				t.Fatalf("expected %s, got %s", tc.expected, got.UTC())
+			}
+			if got.Location() != tc.loc {
+				t.Fatalf("expected location %s, got %s", tc.loc, got.Location())
+			}
+		})
+	}
+}
+
+func TestEngine_Merge_WallClock(t *testing.T) {
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+
+	type span struct {
+		id         int
+		start, end time.Time
+	}
+
+	tcs := []struct {
+		name         string
+		testSchedule func() orderedSchedule
+		trimOverlaps bool
+		expected     []span
+	}{
+		{
+			// On the spring-forward day the wall clock span [01:00, 04:00) only lasts 2 hours.
+			// more desirable event (UTC)   :    [--)
+			// less desirable event (Berlin): [-------)
+			name: "spring forward-trim",
+			testSchedule: func() orderedSchedule {
+				return orderedSchedule{
+					&wallClockEvent{
+						event:    event{ID: 1},
+						Location: berlin,
+						Start:    WallClock{Year: 2021, Month: time.March, Day: 28, Hour: 1},
+						End:      WallClock{Year: 2021, Month: time.March, Day: 28, Hour: 4},
+					},
+					&event{
+						StartTime: time.Date(2021, 3, 28, 0, 30, 0, 0, time.UTC),
+						EndTime:   time.Date(2021, 3, 28, 1, 0, 0, 0, time.UTC),
+						ID:        2,
+					},
+				}
+			},
+			trimOverlaps: true,
+			expected: []span{
+				{1, time.Date(2021, 3, 28, 1, 0, 0, 0, berlin), time.Date(2021, 3, 28, 1, 30, 0, 0, berlin)},
+				{2, time.Date(2021, 3, 28, 0, 30, 0, 0, time.UTC), time.Date(2021, 3, 28, 1, 0, 0, 0, time.UTC)},
+				{1, time.Date(2021, 3, 28, 3, 0, 0, 0, berlin), time.Date(2021, 3, 28, 4, 0, 0, 0, berlin)},
+			},
+		},
+		{
+			// The skipped 02:30 resolves to 03:30 CEST, so the events do not overlap.
+			// more desirable event (Berlin):      [--)
+			// less desirable event (Berlin): [--)
+			name: "spring forward-skipped start-no trim",
+			testSchedule: func() orderedSchedule {
+				return orderedSchedule{
+					&wallClockEvent{
+						event:    event{ID: 1},
+						Location: berlin,
+						Start:    WallClock{Year: 2021, Month: time.March, Day: 28, Hour: 1},
+						End:      WallClock{Year: 2021, Month: time.March, Day: 28, Hour: 3, Minute: 30},
+					},
+					&wallClockEvent{
+						event:    event{ID: 2},
+						Location: berlin,
+						Start:    WallClock{Year: 2021, Month: time.March, Day: 28, Hour: 2, Minute: 30},
+						End:      WallClock{Year: 2021, Month: time.March, Day: 28, Hour: 5},
+					},
+				}
+			},
+			trimOverlaps: false,
+			expected: []span{
+				{1, time.Date(2021, 3, 28, 0, 0, 0, 0, time.UTC).In(berlin), time.Date(2021, 3, 28, 1, 30, 0, 0, time.UTC).In(berlin)},
+				{2, time.Date(2021, 3, 28, 1, 30, 0, 0, time.UTC).In(berlin), time.Date(2021, 3, 28, 3, 0, 0, 0, time.UTC).In(berlin)},
+			},
+		},
+		{
+			// On the fall-back day the wall clock span [01:00, 04:00) lasts 4 hours.
+			// more desirable event (UTC)   :       [--)
+			// less desirable event (Berlin): [----------)
+			name: "fall back-trim",
+			testSchedule: func() orderedSchedule {
+				return orderedSchedule{
+					&wallClockEvent{
+						event:    event{ID: 1},
+						Location: berlin,
+						Start:    WallClock{Year: 2021, Month: time.October, Day: 31, Hour: 1},
+						End:      WallClock{Year: 2021, Month: time.October, Day: 31, Hour: 4},
+					},
+					&event{
+						StartTime: time.Date(2021, 10, 31, 1, 0, 0, 0, time.UTC),
+						EndTime:   time.Date(2021, 10, 31, 2, 0, 0, 0, time.UTC),
+						ID:        2,
+					},
+				}
+			},
+			trimOverlaps: true,
+			expected: []span{
+				{1, time.Date(2021, 10, 30, 23, 0, 0, 0, time.UTC).In(berlin), time.Date(2021, 10, 31, 1, 0, 0, 0, time.UTC).In(berlin)},
+				{2, time.Date(2021, 10, 31, 1, 0, 0, 0, time.UTC), time.Date(2021, 10, 31, 2, 0, 0, 0, time.UTC)},
+				{1, time.Date(2021, 10, 31, 2, 0, 0, 0, time.UTC).In(berlin), time.Date(2021, 10, 31, 3, 0, 0, 0, time.UTC).In(berlin)},
+			},
+		},
+		{
+			// The repeated 02:30 resolves to its first occurrence (02:30 CEST).
+			// more desirable event (Berlin):   [----)
+			// less desirable event (Berlin): [--)
+			name: "fall back-repeated start-trim",
+			testSchedule: func() orderedSchedule {
+				return orderedSchedule{
+					&wallClockEvent{
+						event:    event{ID: 1},
+						Location: berlin,
+						Start:    WallClock{Year: 2021, Month: time.October, Day: 31, Hour: 2},
+						End:      WallClock{Year: 2021, Month: time.October, Day: 31, Hour: 3},
+					},
+					&wallClockEvent{
+						event:    event{ID: 2},
+						Location: berlin,
+						Start:    WallClock{Year: 2021, Month: time.October, Day: 31, Hour: 2, Minute: 30},
+						End:      WallClock{Year: 2021, Month: time.October, Day: 31, Hour: 4},
+					},
+				}
+			},
+			trimOverlaps: true,
+			expected: []span{
+				{1, time.Date(2021, 10, 31, 0, 0, 0, 0, time.UTC).In(berlin), time.Date(2021, 10, 31, 0, 30, 0, 0, time.UTC).In(berlin)},
+				{2, time.Date(2021, 10, 31, 0, 30, 0, 0, time.UTC).In(berlin), time.Date(2021, 10, 31, 3, 0, 0, 0, time.UTC).In(berlin)},
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			e := NewEngine(tc.testSchedule(), tc.trimOverlaps)
+			e.Merge()
+
+			if len(e.MergedSchedule) != len(tc.expected) {
+				t.Fatalf("expected %d events, got %d:\n%s", len(tc.expected), len(e.MergedSchedule), e.Timeline(TimelineOptions{}))
+			}
+			for i, got := range e.MergedSchedule {
+				expected := tc.expected[i]
+				if gotID := testEventID(got); gotID != expected.id {
+					t.Errorf("event %d: expected ID %d, got %d", i+1, expected.id, gotID)
+				}
+				if !got.GetStartTime().Equal(expected.start) || !got.GetEndTime().Equal(expected.end) {
+					t.Errorf("event %d: expected [%s, %s), got [%s, %s)", i+1, expected.start, expected.end,
+						got.GetStartTime(), got.GetEndTime())
+				}
+				// Trimmed parts keep the time zone of the event they originate from.
+				if got.GetStartTime().Location() != expected.start.Location() ||
+					got.GetEndTime().Location() != expected.end.Location() {
+					t.Errorf("event %d: expected locations %s and %s, got %s and %s", i+1,
+						expected.start.Location(), expected.end.Location(),
+						got.GetStartTime().Location(), got.GetEndTime().Location())
+				}
+			}
+		})
+	}
+}
+
+// testEventID returns the ID of the test event types used in this package.
+func testEventID(e Event) int {
+	switch e := e.(type) {
+	case *event:
+		return e.ID
+	case *wallClockEvent:
+		return e.ID
+	default:
+		return 0
+	}
+}